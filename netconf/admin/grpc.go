@@ -0,0 +1,268 @@
+// Package admin exposes a northbound management API for the gateway,
+// separate from the NETCONF service used by configuration clients.
+package admin
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// GRPCService serves the switch.admin.Admin service over gRPC-Web
+// framing (length-prefixed messages over HTTP/1.1) with the JSON codec
+// (application/grpc-web+json), which needs no code generation on the
+// device. The base64 text variant browser clients send
+// (application/grpc-web-text) is accepted and answered in kind.
+// Methods:
+//
+//	/switch.admin.Admin/ListSessions
+//	/switch.admin.Admin/GetStats
+//	/switch.admin.Admin/GetConfig
+//	/switch.admin.Admin/EditConfig
+//	/switch.admin.Admin/StreamEvents
+type GRPCService struct {
+	Srv *server.Server
+}
+
+const grpcServicePrefix = "/switch.admin.Admin/"
+
+// maxGRPCRequest bounds one request message.
+const maxGRPCRequest = 1 << 20
+
+func (s *GRPCService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || len(r.URL.Path) <= len(grpcServicePrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	text := strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web-text")
+	gw := &grpcWriter{w: w, text: text}
+	req, err := readGRPCRequest(r, text)
+	if err != nil {
+		gw.status(3, err.Error()) // INVALID_ARGUMENT
+		return
+	}
+	switch r.URL.Path[len(grpcServicePrefix):] {
+	case "ListSessions":
+		gw.reply(struct {
+			Sessions []server.SessionInfo `json:"sessions"`
+		}{s.Srv.Sessions()})
+	case "GetStats":
+		gw.reply(s.Srv.Stats())
+	case "GetConfig":
+		s.getConfig(gw, req)
+	case "EditConfig":
+		s.editConfig(gw, req)
+	case "StreamEvents":
+		s.streamEvents(gw, r, req)
+	default:
+		gw.status(12, "unknown method") // UNIMPLEMENTED
+	}
+}
+
+// getConfig serves one namespace's state, or every namespace when the
+// request names none, through the server's normal get path.
+func (s *GRPCService) getConfig(gw *grpcWriter, req json.RawMessage) {
+	var in struct {
+		Namespace string `json:"namespace"`
+	}
+	if len(req) > 0 {
+		if err := json.Unmarshal(req, &in); err != nil {
+			gw.status(3, fmt.Sprintf("parse request: %v", err))
+			return
+		}
+	}
+	data, err := s.Srv.GetData(s.Srv.BackgroundContext(), in.Namespace)
+	if err != nil {
+		gw.status(13, err.Error()) // INTERNAL
+		return
+	}
+	gw.reply(struct {
+		Data string `json:"data"`
+	}{data})
+}
+
+// editConfig applies a <config> XML payload through the server's
+// normal edit path, so validation, the change log, intent persistence
+// and notifications all behave exactly as for a NETCONF edit-config.
+func (s *GRPCService) editConfig(gw *grpcWriter, req json.RawMessage) {
+	var in struct {
+		Config string `json:"config"`
+	}
+	if err := json.Unmarshal(req, &in); err != nil || in.Config == "" {
+		gw.status(3, "request needs a config field carrying a <config> XML payload")
+		return
+	}
+	config, err := server.ParseElement([]byte(in.Config))
+	if err != nil {
+		gw.status(3, fmt.Sprintf("parse config: %v", err))
+		return
+	}
+	if config.Local() != "config" {
+		gw.status(3, fmt.Sprintf("config payload must be a <config> element, got <%s>", config.Local()))
+		return
+	}
+	if err := s.Srv.ApplyConfig(s.Srv.BackgroundContext(), config); err != nil {
+		gw.status(13, err.Error())
+		return
+	}
+	gw.reply(struct {
+		OK bool `json:"ok"`
+	}{true})
+}
+
+// streamEvents server-streams published notifications, one message per
+// event, until the client disconnects. The optional request field
+// "streams" restricts delivery to the named streams.
+func (s *GRPCService) streamEvents(gw *grpcWriter, r *http.Request, req json.RawMessage) {
+	var in struct {
+		Streams []string `json:"streams"`
+	}
+	if len(req) > 0 {
+		if err := json.Unmarshal(req, &in); err != nil {
+			gw.status(3, fmt.Sprintf("parse request: %v", err))
+			return
+		}
+	}
+	want := make(map[string]bool, len(in.Streams))
+	for _, st := range in.Streams {
+		want[st] = true
+	}
+	sink := newEventSink()
+	s.Srv.AddSink(sink)
+	defer s.Srv.RemoveSink(sink)
+	flusher, _ := gw.w.(http.Flusher)
+	gw.header()
+	if flusher != nil {
+		flusher.Flush()
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case n := <-sink.ch:
+			if len(want) > 0 && !want[n.Stream] {
+				continue
+			}
+			gw.message(struct {
+				Stream string    `json:"stream"`
+				Time   time.Time `json:"time"`
+				XML    string    `json:"xml"`
+			}{n.Stream, n.Time, n.XML})
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// eventSink buffers published notifications for one streaming client.
+// Publish never blocks, dropping events when the client lags, as the
+// sink contract requires.
+type eventSink struct {
+	ch chan server.Notification
+}
+
+func newEventSink() *eventSink {
+	return &eventSink{ch: make(chan server.Notification, 64)}
+}
+
+func (s *eventSink) Publish(n server.Notification) {
+	select {
+	case s.ch <- n:
+	default:
+	}
+}
+
+// readGRPCRequest reads and unframes the request message: a flags
+// byte, a big-endian length and the JSON payload, base64-encoded as a
+// whole in text mode. A missing frame is a valid empty message.
+func readGRPCRequest(r *http.Request, text bool) (json.RawMessage, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxGRPCRequest))
+	if err != nil {
+		return nil, err
+	}
+	if text {
+		body, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(body)))
+		if err != nil {
+			return nil, fmt.Errorf("bad base64 request body: %v", err)
+		}
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if len(body) < 5 {
+		return nil, fmt.Errorf("truncated request frame")
+	}
+	n := binary.BigEndian.Uint32(body[1:5])
+	if int(n) > len(body)-5 {
+		return nil, fmt.Errorf("request frame length %d exceeds body", n)
+	}
+	return json.RawMessage(body[5 : 5+int(n)]), nil
+}
+
+// grpcWriter emits gRPC-Web response frames, base64-encoding each one
+// when the client spoke the text variant.
+type grpcWriter struct {
+	w           http.ResponseWriter
+	text        bool
+	wroteHeader bool
+}
+
+func (g *grpcWriter) header() {
+	if g.wroteHeader {
+		return
+	}
+	ct := "application/grpc-web+json"
+	if g.text {
+		ct = "application/grpc-web-text+json"
+	}
+	g.w.Header().Set("Content-Type", ct)
+	g.wroteHeader = true
+}
+
+// frame writes one frame: a flags byte, a big-endian length and the
+// payload. Flag 0x80 marks the trailers frame.
+func (g *grpcWriter) frame(flags byte, payload []byte) {
+	g.header()
+	var hdr [5]byte
+	hdr[0] = flags
+	binary.BigEndian.PutUint32(hdr[1:], uint32(len(payload)))
+	buf := append(hdr[:], payload...)
+	if g.text {
+		io.WriteString(g.w, base64.StdEncoding.EncodeToString(buf))
+		return
+	}
+	g.w.Write(buf)
+}
+
+// message writes one JSON-encoded response message frame.
+func (g *grpcWriter) message(v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		g.status(13, err.Error())
+		return
+	}
+	g.frame(0, body)
+}
+
+// reply finishes a unary call: the response message and an OK status.
+func (g *grpcWriter) reply(v any) {
+	g.message(v)
+	g.status(0, "")
+}
+
+// status writes the trailers frame carrying the gRPC status.
+func (g *grpcWriter) status(code int, msg string) {
+	trailers := fmt.Sprintf("grpc-status: %d\r\n", code)
+	if msg != "" {
+		trailers += "grpc-message: " + msg + "\r\n"
+	}
+	g.frame(0x80, []byte(trailers))
+}
@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/miyagi"
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// RESTHandler serves the plain JSON admin endpoints:
+//
+//	GET /admin/sessions  — active NETCONF sessions
+//	GET /admin/stats     — server-wide counters
+//	GET /admin/miyagi    — per-UID latency and error-rate metrics
+//	GET /capabilities    — the capability list from the NETCONF hello
+type RESTHandler struct {
+	Srv     *server.Server
+	Metrics *miyagi.Metrics
+}
+
+func (h *RESTHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var body any
+	switch r.URL.Path {
+	case "/admin/sessions":
+		body = struct {
+			Sessions []server.SessionInfo `json:"sessions"`
+		}{h.Srv.Sessions()}
+	case "/admin/stats":
+		body = h.Srv.Stats()
+	case "/admin/miyagi":
+		if h.Metrics == nil {
+			http.Error(w, "miyagi metrics not enabled", http.StatusNotFound)
+			return
+		}
+		body = struct {
+			UIDs []miyagi.UIDMetric `json:"uids"`
+		}{h.Metrics.Snapshot()}
+	case "/capabilities":
+		body = struct {
+			Capabilities []string `json:"capabilities"`
+		}{h.Srv.Capabilities()}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(body)
+}
+
+// ListenAndServe serves the admin APIs (REST and gRPC-Web) on addr.
+// metrics may be nil when Miyagi metric collection is disabled. A
+// non-empty token requires every request to carry it as a bearer
+// token; an empty one leaves the API open, which is only sane on a
+// loopback or management-VRF bind, so it is called out in the log.
+func ListenAndServe(addr string, srv *server.Server, metrics *miyagi.Metrics, token string) error {
+	return http.ListenAndServe(addr, NewHandler(srv, metrics, token))
+}
+
+// NewHandler builds the admin HTTP handler with every endpoint
+// mounted, wrapped in bearer-token authentication when token is
+// non-empty.
+func NewHandler(srv *server.Server, metrics *miyagi.Metrics, token string) http.Handler {
+	mux := NewMux(srv, metrics)
+	if token == "" {
+		log.Printf("admin: no -admin-token configured, API is unauthenticated")
+		return mux
+	}
+	return requireBearer(token, mux)
+}
+
+// NewMux builds the admin HTTP mux with every admin endpoint mounted.
+func NewMux(srv *server.Server, metrics *miyagi.Metrics) *http.ServeMux {
+	mux := http.NewServeMux()
+	rest := &RESTHandler{Srv: srv, Metrics: metrics}
+	mux.Handle(grpcServicePrefix, &GRPCService{Srv: srv})
+	mux.Handle("/admin/", rest)
+	mux.Handle("/capabilities", rest)
+	return mux
+}
+
+// requireBearer rejects requests whose Authorization header does not
+// carry the expected bearer token. The comparison is constant-time so
+// the token cannot be guessed byte by byte.
+func requireBearer(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,387 @@
+// Command netconfd runs the NETCONF-to-Miyagi gateway on the switch.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/admin"
+	"github.com/rahulrock213/switch/netconf/handlers"
+	"github.com/rahulrock213/switch/netconf/miyagi"
+	"github.com/rahulrock213/switch/netconf/notify"
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+func main() {
+	listen := flag.String("listen", ":830", "NETCONF listen address")
+	configPath := flag.String("config", "", "structured JSON configuration file with per-handler settings")
+	listenUnix := flag.String("listen-unix", "", "also accept NETCONF sessions on this unix socket, for local co-processes (empty = disabled)")
+	sshListen := flag.String("ssh-listen", "", "serve NETCONF over SSH (netconf subsystem, RFC 6242) on this address (empty = disabled)")
+	sshHostKey := flag.String("ssh-hostkey", "/etc/netconfd/hostkey", "SSH host key file; generated on first use when missing")
+	sshAuthKeys := flag.String("ssh-authorized-keys", "", "authorized_keys file for SSH public-key authentication (required with -ssh-listen)")
+	miyagiSock := flag.String("miyagi", "/var/run/miyagi.sock", "Miyagi daemon unix socket; proxy mode takes name=path pairs separated by commas (first entry is the default device)")
+	adminAddr := flag.String("admin", "", "serve the admin APIs (REST and gRPC-Web) on this address (empty = disabled)")
+	adminToken := flag.String("admin-token", "", "bearer token required on every admin API request (empty = unauthenticated)")
+	intentPath := flag.String("intent", "", "persist applied configuration to this file and re-apply it on startup (empty = disabled)")
+	templatesPath := flag.String("templates", "", "persist provisioning templates to this JSON file (empty = in-memory only)")
+	handlerTimeout := flag.Duration("handler-timeout", 30*time.Second, "default per-handler call timeout (0 = none)")
+	trace := flag.Bool("trace-frames", false, "log every NETCONF frame sent and received (debug)")
+	cliPassthrough := flag.Bool("enable-cli-passthrough", false, "allow the raw device-CLI passthrough RPC")
+	strict := flag.Bool("strict-replies", false, "emit fully RFC-compliant reply framing (XML declaration) for strict clients")
+	strictIDs := flag.Bool("strict-message-ids", false, "require numeric, strictly increasing message-ids per session (rejects replayed frames)")
+	ratesWindow := flag.Duration("rates-window", 30*time.Second, "sampling window for computed interface rates (0 = disabled)")
+	driftThreshold := flag.Duration("drift-threshold", 5*time.Second, "notify when device clock drifts beyond this (0 = disabled)")
+	reconcile := flag.Duration("reconcile-interval", 10*time.Minute, "check device state against persisted intent this often (0 = disabled; requires -intent)")
+	slowLog := flag.Duration("slow-handler-log", 0, "log handler calls slower than this (0 = disabled)")
+	errMap := flag.String("miyagi-errors", "", "JSON file extending the Miyagi status code to NETCONF error-tag mapping")
+	selftestReport := flag.String("selftest-report", "", "also write the startup self-test report to this JSON file")
+	selftestStrict := flag.Bool("selftest-strict", false, "refuse to start when a critical self-test check fails")
+	writeTimeout := flag.Duration("write-timeout", 30*time.Second, "per-session frame write deadline; clients that stop draining replies are dropped (0 = none)")
+	drainTimeout := flag.Duration("drain-timeout", time.Minute, "how long a hitless restart waits for active sessions to end before closing them (0 = forever)")
+	queueDepth := flag.Int("session-queue", 0, "pending RPCs allowed per session before resource-denied (0 = default)")
+	maxBackend := flag.Int("max-backend-calls", 0, "handler calls allowed to run concurrently across sessions (0 = unlimited)")
+	allowFrom := flag.String("allow-from", "", "comma-separated CIDRs permitted to connect (empty = all)")
+	denyFrom := flag.String("deny-from", "", "comma-separated CIDRs refused at accept time")
+	aclFile := flag.String("acl-file", "", `JSON file with {"allow":[...],"deny":[...]} CIDR lists; overrides the flags and is re-read on SIGUSR1`)
+	autoVlans := flag.Bool("auto-create-vlans", false, "create missing VLANs referenced by port assignments instead of failing")
+	skipNoop := flag.Bool("skip-noop-edits", false, "skip edit items matching the stored intent when device state confirms them (requires -intent)")
+	applyWorkers := flag.Int("apply-workers", 0, "concurrent item groups per edit-config (0 = default of 8)")
+	nsTimeouts := flag.String("handler-timeouts", "", "per-namespace timeout overrides, e.g. yang:vlan=5s,yang:route=10s")
+	snmpTarget := flag.String("snmp-agent", "", `SNMP agent the OID bridge queries, "host" or "host:port" (empty = 127.0.0.1:161)`)
+	snmpCommunity := flag.String("snmp-community", "", "SNMP community for the OID bridge (empty = public)")
+	mqttAddr := flag.String("mqtt", "", "publish notifications to this MQTT broker (host:port, empty = disabled)")
+	mqttPrefix := flag.String("mqtt-topic", "switch/notifications", "MQTT topic prefix for notifications")
+	record := flag.String("record", "", "record Miyagi traffic to this JSONL file")
+	replay := flag.String("replay", "", "serve Miyagi calls from this recorded JSONL file instead of the daemon")
+	flag.Parse()
+
+	var caller miyagi.Caller
+	devices := make(map[string]miyagi.Caller)
+	if *replay != "" {
+		replayer, err := miyagi.NewReplayer(*replay)
+		if err != nil {
+			log.Fatalf("netconfd: %v", err)
+		}
+		caller = replayer
+	} else if strings.Contains(*miyagiSock, "=") {
+		// Proxy mode: one connection per named device.
+		for i, pair := range strings.Split(*miyagiSock, ",") {
+			name, path, ok := strings.Cut(pair, "=")
+			if !ok {
+				log.Fatalf("netconfd: -miyagi: bad device entry %q", pair)
+			}
+			client, err := miyagi.Dial(path)
+			if err != nil {
+				log.Fatalf("netconfd: device %s: %v", name, err)
+			}
+			defer client.Close()
+			devices[name] = client
+			if i == 0 {
+				caller = client
+			}
+		}
+	} else {
+		client, err := miyagi.Dial(*miyagiSock)
+		if err != nil {
+			log.Fatalf("netconfd: %v", err)
+		}
+		defer client.Close()
+		caller = client
+	}
+	if *record != "" {
+		recorder, err := miyagi.NewRecorder(caller, *record)
+		if err != nil {
+			log.Fatalf("netconfd: %v", err)
+		}
+		defer recorder.Close()
+		caller = recorder
+	}
+	metrics := miyagi.NewMetrics(caller)
+	caller = metrics
+
+	report, failed := runSelfTest(caller, *listen, *intentPath)
+	writeSelfTestReport(report, *selftestReport)
+	if failed && *selftestStrict {
+		log.Fatalf("netconfd: critical self-test failure, refusing to start")
+	}
+
+	srv := server.New(caller)
+	features, err := miyagi.Probe(context.Background(), caller)
+	if err != nil {
+		log.Fatalf("netconfd: probe capabilities: %v", err)
+	}
+	srv.Features = features
+	srv.Devices = devices
+	info, err := miyagi.ProbeSystemInfo(context.Background(), caller)
+	if err != nil {
+		log.Fatalf("netconfd: probe system info: %v", err)
+	}
+	if info.Model != "" {
+		srv.Profile = handlers.ProfileForModel(info.Model)
+	}
+	if *configPath != "" {
+		cfg, err := server.LoadConfig(*configPath)
+		if err != nil {
+			log.Fatalf("netconfd: -config: %v", err)
+		}
+		srv.Config = cfg
+	}
+	srv.DefaultHandlerTimeout = *handlerTimeout
+	srv.TraceFrames = *trace
+	srv.AllowCLIPassthrough = *cliPassthrough
+	srv.StrictReplies = *strict
+	srv.StrictMessageIDs = *strictIDs
+	srv.WriteTimeout = *writeTimeout
+	srv.SessionQueueDepth = *queueDepth
+	srv.MaxBackendCalls = *maxBackend
+	srv.AutoCreateVlans = *autoVlans
+	srv.SkipNoopEdits = *skipNoop
+	srv.ApplyWorkers = *applyWorkers
+	if *aclFile != "" {
+		allow, deny, err := loadACLFile(*aclFile)
+		if err != nil {
+			log.Fatalf("netconfd: -acl-file: %v", err)
+		}
+		*allowFrom, *denyFrom = allow, deny
+	}
+	if *allowFrom != "" || *denyFrom != "" {
+		acl, err := server.ParseAccessList(*allowFrom, *denyFrom)
+		if err != nil {
+			log.Fatalf("netconfd: %v", err)
+		}
+		srv.ACL = acl
+	}
+	if *nsTimeouts != "" {
+		timeouts, err := parseTimeouts(*nsTimeouts)
+		if err != nil {
+			log.Fatalf("netconfd: -handler-timeouts: %v", err)
+		}
+		srv.HandlerTimeouts = timeouts
+	}
+	if *slowLog > 0 {
+		srv.Use(server.SlowCallLog(*slowLog))
+	}
+	if *errMap != "" {
+		if err := server.LoadMiyagiErrorMap(*errMap); err != nil {
+			log.Fatalf("netconfd: -miyagi-errors: %v", err)
+		}
+	}
+	handlers.RegisterAll(srv)
+	go handlers.WatchSTPTopology(srv)
+	go handlers.WatchTransceiverAlarms(srv)
+	go handlers.WatchInterfaceRates(srv, *ratesWindow)
+	go handlers.WatchClockDrift(srv, *driftThreshold)
+	if *intentPath != "" {
+		store, err := server.OpenIntentStore(*intentPath)
+		if err != nil {
+			log.Fatalf("netconfd: %v", err)
+		}
+		srv.Intent = store
+		srv.RestoreIntent()
+		go handlers.WatchConfigDrift(srv, *reconcile)
+	}
+	if *snmpTarget != "" || *snmpCommunity != "" {
+		target := *snmpTarget
+		if target == "" {
+			target = "127.0.0.1"
+		}
+		if err := handlers.SetSNMPAgent(target, *snmpCommunity); err != nil {
+			log.Fatalf("netconfd: -snmp-agent: %v", err)
+		}
+	}
+	if *templatesPath != "" {
+		if err := handlers.SetTemplateStore(*templatesPath); err != nil {
+			log.Fatalf("netconfd: -templates: %v", err)
+		}
+	}
+	if *mqttAddr != "" {
+		srv.AddSink(notify.NewMQTTPublisher(*mqttAddr, "netconfd", *mqttPrefix))
+	}
+	if *adminAddr != "" {
+		go func() {
+			if err := admin.ListenAndServe(*adminAddr, srv, metrics, *adminToken); err != nil {
+				log.Fatalf("netconfd: admin: %v", err)
+			}
+		}()
+	}
+	ln, err := inheritOrListen(*listen)
+	if err != nil {
+		log.Fatalf("netconfd: %v", err)
+	}
+	if *listenUnix != "" {
+		uln, err := listenUnixSocket(*listenUnix)
+		if err != nil {
+			log.Fatalf("netconfd: -listen-unix: %v", err)
+		}
+		log.Printf("netconfd: listening on unix socket %s", *listenUnix)
+		go func() {
+			if err := srv.Serve(uln); err != nil {
+				log.Fatalf("netconfd: unix listener: %v", err)
+			}
+		}()
+	}
+	if *sshListen != "" {
+		sshCfg, err := sshServerConfig(*sshHostKey, *sshAuthKeys)
+		if err != nil {
+			log.Fatalf("netconfd: ssh: %v", err)
+		}
+		sln, err := net.Listen("tcp", *sshListen)
+		if err != nil {
+			log.Fatalf("netconfd: -ssh-listen: %v", err)
+		}
+		log.Printf("netconfd: listening for SSH on %s", *sshListen)
+		go func() {
+			if err := srv.ServeSSH(sln, sshCfg); err != nil {
+				log.Fatalf("netconfd: ssh listener: %v", err)
+			}
+		}()
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1)
+		for s := range sig {
+			if s == syscall.SIGUSR1 {
+				if *aclFile == "" || srv.ACL == nil {
+					continue
+				}
+				allow, deny, err := loadACLFile(*aclFile)
+				if err == nil {
+					err = srv.ACL.Reload(allow, deny)
+				}
+				if err != nil {
+					log.Printf("netconfd: acl reload: %v", err)
+				} else {
+					log.Printf("netconfd: acl reloaded from %s", *aclFile)
+				}
+				continue
+			}
+			if s == syscall.SIGHUP {
+				// Hitless restart: hand the listening socket to a
+				// fresh copy of ourselves, then serve the in-flight
+				// sessions to completion before exiting.
+				if err := spawnSuccessor(ln); err != nil {
+					log.Printf("netconfd: restart failed: %v", err)
+					continue
+				}
+				log.Printf("netconfd: handed listener to successor, draining")
+				srv.Drain(*drainTimeout)
+			} else {
+				log.Printf("netconfd: shutting down")
+				srv.Shutdown()
+			}
+			os.Exit(0)
+		}
+	}()
+	log.Printf("netconfd: listening on %s", ln.Addr())
+	if err := srv.Serve(ln); err != nil {
+		log.Fatalf("netconfd: %v", err)
+	}
+}
+
+// listenFDEnv names the env var carrying the inherited listener FD.
+const listenFDEnv = "NETCONFD_LISTEN_FD"
+
+// inheritOrListen reuses the listener inherited from a predecessor
+// process when present, otherwise opens a fresh one.
+func inheritOrListen(addr string) (net.Listener, error) {
+	if fdStr := os.Getenv(listenFDEnv); fdStr != "" {
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", listenFDEnv, err)
+		}
+		f := os.NewFile(uintptr(fd), "inherited-listener")
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inherit listener: %w", err)
+		}
+		log.Printf("netconfd: inherited listener from predecessor")
+		return ln, nil
+	}
+	return net.Listen("tcp", addr)
+}
+
+// spawnSuccessor starts a new netconfd with the listening socket
+// passed as fd 3, so no incoming connection is dropped during the
+// restart window.
+func spawnSuccessor(ln net.Listener) error {
+	tcp, ok := ln.(*net.TCPListener)
+	if !ok {
+		return fmt.Errorf("listener type %T cannot be handed over", ln)
+	}
+	f, err := tcp.File()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{f} // becomes fd 3 in the child
+	cmd.Env = append(os.Environ(), listenFDEnv+"=3")
+	return cmd.Start()
+}
+
+// listenUnixSocket opens the local NETCONF unix socket. Local
+// co-processes (web UI, zero-touch agent) speak the same protocol over
+// it without TCP overhead; the ACL does not apply to it. A stale
+// socket file from a previous run is removed first, and unlink-on-close
+// is disabled so a draining predecessor does not remove the socket its
+// successor just bound during a hitless restart.
+func listenUnixSocket(path string) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	return ln, nil
+}
+
+// loadACLFile reads the {"allow":[...],"deny":[...]} ACL file and
+// returns both lists in the comma-separated form ParseAccessList and
+// Reload take.
+func loadACLFile(path string) (allow, deny string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	var lists struct {
+		Allow []string `json:"allow"`
+		Deny  []string `json:"deny"`
+	}
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return "", "", fmt.Errorf("parse %s: %v", path, err)
+	}
+	return strings.Join(lists.Allow, ","), strings.Join(lists.Deny, ","), nil
+}
+
+// parseTimeouts parses "ns=dur,ns=dur" override lists.
+func parseTimeouts(s string) (map[string]time.Duration, error) {
+	out := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		ns, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("bad entry %q", pair)
+		}
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("bad duration in %q: %v", pair, err)
+		}
+		out[ns] = d
+	}
+	return out, nil
+}
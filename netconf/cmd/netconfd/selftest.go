@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/miyagi"
+)
+
+// diagnostic is one self-test result in the startup report.
+type diagnostic struct {
+	Check    string `json:"check"`
+	Status   string `json:"status"` // "ok", "failed" or "skipped"
+	Detail   string `json:"detail,omitempty"`
+	Critical bool   `json:"critical"`
+}
+
+// selfTestTimeout bounds the Miyagi round-trip check so a hung daemon
+// cannot stall startup indefinitely.
+const selfTestTimeout = 5 * time.Second
+
+// runSelfTest verifies the daemon's external dependencies before
+// serving: the Miyagi socket answers a cheap call, the listen address
+// is bindable, and the intent file (when configured) is writable. It
+// returns the diagnostics and whether any critical check failed.
+func runSelfTest(caller miyagi.Caller, listen, intentPath string) ([]diagnostic, bool) {
+	var report []diagnostic
+
+	// Any answer from the daemon proves the round trip, including a
+	// daemon-level error for an unknown UID.
+	ctx, cancel := context.WithTimeout(context.Background(), selfTestTimeout)
+	d := diagnostic{Check: "miyagi-roundtrip", Status: "ok", Critical: true}
+	if _, err := caller.Call(ctx, "system.info.get", nil); err != nil {
+		if _, ok := err.(*miyagi.Error); !ok {
+			d.Status = "failed"
+			d.Detail = err.Error()
+		}
+	}
+	cancel()
+	report = append(report, d)
+
+	d = diagnostic{Check: "listen-bindable", Status: "ok", Critical: true}
+	if os.Getenv(listenFDEnv) != "" {
+		d.Status = "skipped"
+		d.Detail = "listener inherited from predecessor"
+	} else if ln, err := net.Listen("tcp", listen); err != nil {
+		d.Status = "failed"
+		d.Detail = err.Error()
+	} else {
+		ln.Close()
+	}
+	report = append(report, d)
+
+	d = diagnostic{Check: "intent-writable", Status: "ok"}
+	if intentPath == "" {
+		d.Status = "skipped"
+		d.Detail = "intent persistence disabled"
+	} else if f, err := os.OpenFile(intentPath, os.O_CREATE|os.O_WRONLY, 0o600); err != nil {
+		d.Status = "failed"
+		d.Detail = err.Error()
+	} else {
+		f.Close()
+	}
+	report = append(report, d)
+
+	failed := false
+	for _, d := range report {
+		if d.Status == "failed" && d.Critical {
+			failed = true
+		}
+	}
+	return report, failed
+}
+
+// writeSelfTestReport logs every diagnostic and, when path is set,
+// also writes the report as JSON for external tooling.
+func writeSelfTestReport(report []diagnostic, path string) {
+	for _, d := range report {
+		if d.Detail != "" {
+			log.Printf("netconfd: selftest %s: %s (%s)", d.Check, d.Status, d.Detail)
+		} else {
+			log.Printf("netconfd: selftest %s: %s", d.Check, d.Status)
+		}
+	}
+	if path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Printf("netconfd: selftest report: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		log.Printf("netconfd: selftest report: %v", err)
+	}
+}
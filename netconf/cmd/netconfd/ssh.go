@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshServerConfig builds the SSH server configuration for the NETCONF
+// transport: the host key from hostKeyPath (generated on first use when
+// the file is missing) and public-key authentication against the
+// authorized_keys file at authKeysPath.
+func sshServerConfig(hostKeyPath, authKeysPath string) (*ssh.ServerConfig, error) {
+	if authKeysPath == "" {
+		return nil, fmt.Errorf("-ssh-authorized-keys is required with -ssh-listen")
+	}
+	authorized, err := loadAuthorizedKeys(authKeysPath)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := loadHostKey(hostKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if authorized[string(key.Marshal())] {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown public key for user %q", meta.User())
+		},
+	}
+	cfg.AddHostKey(signer)
+	return cfg, nil
+}
+
+// loadHostKey reads the PEM host key at path. When the file does not
+// exist a fresh ed25519 key is generated and persisted there, so a
+// factory-reset switch gets a stable host identity on first boot.
+func loadHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return generateHostKey(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse host key %s: %v", path, err)
+	}
+	return signer, nil
+}
+
+func generateHostKey(path string) (ssh.Signer, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		return nil, fmt.Errorf("persist host key: %v", err)
+	}
+	log.Printf("netconfd: generated SSH host key at %s", path)
+	return ssh.NewSignerFromKey(priv)
+}
+
+// loadAuthorizedKeys parses an OpenSSH authorized_keys file into a set
+// keyed by the wire form of each public key.
+func loadAuthorizedKeys(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool)
+	for len(data) > 0 {
+		key, _, _, rest, err := ssh.ParseAuthorizedKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %v", path, err)
+		}
+		keys[string(key.Marshal())] = true
+		data = rest
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s contains no keys", path)
+	}
+	return keys, nil
+}
@@ -0,0 +1,112 @@
+package conformance
+
+import (
+	"bufio"
+	"fmt"
+	"testing"
+
+	"github.com/rahulrock213/switch/netconf/handlers"
+	"github.com/rahulrock213/switch/netconf/netconfutil"
+)
+
+// repeatReader yields the same byte sequence forever, so frame-reading
+// benchmarks are not bounded by buffer construction.
+type repeatReader struct {
+	data []byte
+	off  int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	n := copy(p, r.data[r.off:])
+	r.off = (r.off + n) % len(r.data)
+	return n, nil
+}
+
+// BenchmarkReadFrame measures scanning one ~2 KiB frame out of the
+// byte stream, the per-RPC floor every session pays.
+func BenchmarkReadFrame(b *testing.B) {
+	var body string
+	for i := 0; i < 40; i++ {
+		body += fmt.Sprintf("<vlan><id>%d</id><name>bench-vlan-%d</name></vlan>", i, i)
+	}
+	frame := []byte(netconfutil.Frame(fmt.Sprintf(`<rpc-reply message-id="1" xmlns=%q><data>%s</data></rpc-reply>`, netconfutil.BaseNS, body)))
+	c := &Client{br: bufio.NewReader(&repeatReader{data: frame})}
+	b.SetBytes(int64(len(frame)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ReadFrame(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDispatchGet measures a full filtered-get round trip over the
+// transport: framing, parse, dispatch, handler and reply.
+func BenchmarkDispatchGet(b *testing.B) {
+	h := Start(b, handlers.RegisterAll)
+	type vlan struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	vlans := make([]vlan, 64)
+	for i := range vlans {
+		vlans[i] = vlan{ID: i + 1, Name: fmt.Sprintf("bench-%d", i+1)}
+	}
+	h.Mock.Reply("vlan.get", vlans)
+	c := h.Dial(b)
+	get := fmt.Sprintf(`<get><filter><vlans xmlns=%q/></filter></get>`, handlers.NSVlan)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.RPC(get); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := c.ReadFrame(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkInterfacesGet measures the ip-interface get path with a
+// realistic 48-port table, the heaviest of the routine state reads.
+func BenchmarkInterfacesGet(b *testing.B) {
+	h := Start(b, handlers.RegisterAll)
+	type address struct {
+		IP        string `json:"ip"`
+		Mask      string `json:"mask"`
+		Secondary bool   `json:"secondary"`
+	}
+	type intf struct {
+		Name      string    `json:"ifname"`
+		Addresses []address `json:"addresses"`
+		MTU       int       `json:"mtu"`
+	}
+	intfs := make([]intf, 48)
+	for i := range intfs {
+		intfs[i] = intf{
+			Name:      fmt.Sprintf("GigabitEthernet1/0/%d", i+1),
+			Addresses: []address{{IP: fmt.Sprintf("10.0.%d.1", i), Mask: "255.255.255.0"}},
+			MTU:       1518,
+		}
+	}
+	h.Mock.Reply("ip.interface.get", intfs)
+	c := h.Dial(b)
+	get := fmt.Sprintf(`<get><filter><interfaces xmlns=%q/></filter></get>`, handlers.NSIpInterface)
+	// One warm-up round trip sizes the reply for the throughput figure.
+	if _, err := c.RPC(get); err != nil {
+		b.Fatal(err)
+	}
+	reply, err := c.ReadFrame()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(reply)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.RPC(get); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := c.ReadFrame(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package conformance is a wire-protocol test harness for the NETCONF
+// server: a mock Miyagi backend, a scripted client that speaks the
+// end-of-message framing by hand, and a reusable suite of protocol
+// checks. It lives outside the server's own tests so downstream forks
+// can run the same suite against their modified servers.
+package conformance
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rahulrock213/switch/netconf/miyagi"
+	"github.com/rahulrock213/switch/netconf/netconfutil"
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// MockMiyagi is an in-memory miyagi.Caller for tests. UIDs without a
+// seeded reply return a JSON null, which the handlers decode as empty
+// state; seeded failures come back as *miyagi.Error the way the real
+// daemon reports them.
+type MockMiyagi struct {
+	mu      sync.Mutex
+	replies map[string]json.RawMessage
+	errors  map[string]*miyagi.Error
+	calls   []string
+}
+
+// NewMock builds an empty MockMiyagi.
+func NewMock() *MockMiyagi {
+	return &MockMiyagi{
+		replies: make(map[string]json.RawMessage),
+		errors:  make(map[string]*miyagi.Error),
+	}
+}
+
+// Reply seeds the return payload for one UID; payload is marshalled to
+// JSON. Reply panics on unmarshalable payloads, which is always a test
+// bug.
+func (m *MockMiyagi) Reply(uid string, payload any) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		panic(fmt.Sprintf("conformance: marshal reply for %s: %v", uid, err))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replies[uid] = raw
+}
+
+// Fail makes one UID return a daemon error with the given status.
+func (m *MockMiyagi) Fail(uid string, status int, msg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[uid] = &miyagi.Error{UID: uid, Status: status, Msg: msg}
+}
+
+// Call implements miyagi.Caller.
+func (m *MockMiyagi) Call(ctx context.Context, uid string, arg any) (json.RawMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, uid)
+	if err, ok := m.errors[uid]; ok {
+		return nil, err
+	}
+	if raw, ok := m.replies[uid]; ok {
+		return raw, nil
+	}
+	return json.RawMessage("null"), nil
+}
+
+// Calls returns the UIDs called so far, in order.
+func (m *MockMiyagi) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+// Harness is one server under test listening on a loopback port.
+type Harness struct {
+	Server *server.Server
+	Mock   *MockMiyagi
+	Addr   string
+}
+
+// Start builds a server over a fresh mock backend, lets register wire
+// its handlers, and serves it on a loopback listener torn down with the
+// test.
+func Start(tb testing.TB, register func(*server.Server)) *Harness {
+	tb.Helper()
+	mock := NewMock()
+	srv := server.New(mock)
+	if register != nil {
+		register(srv)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("listen: %v", err)
+	}
+	go srv.Serve(ln)
+	tb.Cleanup(srv.Shutdown)
+	return &Harness{Server: srv, Mock: mock, Addr: ln.Addr().String()}
+}
+
+// Client is a scripted NETCONF client. It frames and parses by hand so
+// tests can send deliberately malformed traffic.
+type Client struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	// ServerHello is the raw hello frame the server sent on connect.
+	ServerHello string
+
+	nextID int
+}
+
+// Dial connects to the harness, consumes the server hello and sends a
+// plain client hello.
+func (h *Harness) Dial(tb testing.TB) *Client {
+	tb.Helper()
+	c, err := h.dial(nil)
+	if err != nil {
+		tb.Fatalf("dial %s: %v", h.Addr, err)
+	}
+	tb.Cleanup(func() { c.Close() })
+	return c
+}
+
+func (h *Harness) dial(clientCaps []string) (*Client, error) {
+	conn, err := net.Dial("tcp", h.Addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{conn: conn, br: bufio.NewReader(conn)}
+	if c.ServerHello, err = c.ReadFrame(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("read server hello: %w", err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<hello xmlns=%q><capabilities>`, netconfutil.BaseNS)
+	caps := append([]string{"urn:ietf:params:netconf:base:1.0"}, clientCaps...)
+	for _, cap := range caps {
+		fmt.Fprintf(&b, "<capability>%s</capability>", cap)
+	}
+	b.WriteString("</capabilities></hello>")
+	if err := c.SendFrame(b.String()); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("send client hello: %w", err)
+	}
+	return c, nil
+}
+
+// SendFrame writes raw followed by the end-of-message marker.
+func (c *Client) SendFrame(raw string) error {
+	_, err := io.WriteString(c.conn, netconfutil.Frame(raw))
+	return err
+}
+
+// SendRaw writes bytes to the transport verbatim, for framing tests
+// that control the marker themselves.
+func (c *Client) SendRaw(raw string) error {
+	_, err := io.WriteString(c.conn, raw)
+	return err
+}
+
+// ReadFrame reads one end-of-message delimited frame.
+func (c *Client) ReadFrame() (string, error) {
+	var b strings.Builder
+	for {
+		chunk, err := c.br.ReadString('>')
+		b.WriteString(chunk)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasSuffix(b.String(), netconfutil.EOMMarker) {
+			return strings.TrimSuffix(b.String(), netconfutil.EOMMarker), nil
+		}
+	}
+}
+
+// RPC wraps inner in an <rpc> envelope with a fresh message-id, sends
+// it and returns the message-id used.
+func (c *Client) RPC(inner string) (string, error) {
+	c.nextID++
+	msgID := fmt.Sprintf("%d", c.nextID)
+	return msgID, c.SendFrame(fmt.Sprintf(`<rpc message-id=%q xmlns=%q>%s</rpc>`, msgID, netconfutil.BaseNS, inner))
+}
+
+// Close tears down the transport.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,69 @@
+package conformance
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rahulrock213/switch/netconf/handlers"
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// TestProtocolConformance runs the reusable suite against the stock
+// handler wiring, the same set cmd/netconfd registers.
+func TestProtocolConformance(t *testing.T) {
+	RunSuite(t, handlers.RegisterAll)
+}
+
+// TestEditReachesBackend verifies an edit-config travels the whole way:
+// framing, dispatch, handler and finally the Miyagi call, and that the
+// client gets <ok/>.
+func TestEditReachesBackend(t *testing.T) {
+	h := Start(t, handlers.RegisterAll)
+	c := h.Dial(t)
+	msgID, err := c.RPC(fmt.Sprintf(
+		`<edit-config><config><vlan xmlns=%q><id>42</id><name>conformance</name></vlan></config></edit-config>`,
+		handlers.NSVlan))
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	reply := mustReadReply(t, c)
+	if reply.Attr("message-id") != msgID {
+		t.Fatalf("reply message-id %q, want %q", reply.Attr("message-id"), msgID)
+	}
+	if reply.Child("ok") == nil {
+		t.Fatalf("edit-config did not return <ok/>:\n%s", server.RenderElement(reply))
+	}
+	if calls := h.Mock.Calls(); !containsCall(calls, "vlan.create") {
+		t.Errorf("backend never saw vlan.create; calls: %v", calls)
+	}
+}
+
+// TestBackendErrorSurfaces verifies a daemon rejection comes back as an
+// rpc-error carrying the daemon's message.
+func TestBackendErrorSurfaces(t *testing.T) {
+	h := Start(t, handlers.RegisterAll)
+	h.Mock.Fail("vlan.create", 2, "vlan id out of range")
+	c := h.Dial(t)
+	if _, err := c.RPC(fmt.Sprintf(
+		`<edit-config><config><vlan xmlns=%q><id>99</id></vlan></config></edit-config>`, handlers.NSVlan)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	reply := mustReadReply(t, c)
+	errElem := reply.Child("rpc-error")
+	if errElem == nil {
+		t.Fatalf("backend failure got no rpc-error:\n%s", server.RenderElement(reply))
+	}
+	if !strings.Contains(errElem.ChildText("error-message"), "vlan id out of range") {
+		t.Errorf("rpc-error does not carry the daemon message: %q", errElem.ChildText("error-message"))
+	}
+}
+
+func containsCall(calls []string, uid string) bool {
+	for _, c := range calls {
+		if c == uid {
+			return true
+		}
+	}
+	return false
+}
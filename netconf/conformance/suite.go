@@ -0,0 +1,173 @@
+package conformance
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rahulrock213/switch/netconf/netconfutil"
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// RunSuite runs the protocol conformance checks against one server
+// wiring: hello exchange, framing variants, malformed input, pipelined
+// RPCs and a filtered get against every advertised namespace. Forks
+// call it from their own tests with their handler registration.
+func RunSuite(t *testing.T, register func(*server.Server)) {
+	h := Start(t, register)
+	t.Run("hello", func(t *testing.T) { checkHello(t, h) })
+	t.Run("framing", func(t *testing.T) { checkFraming(t, h) })
+	t.Run("bad-xml", func(t *testing.T) { checkBadXML(t, h) })
+	t.Run("not-a-shell", func(t *testing.T) { checkShellGuidance(t, h) })
+	t.Run("pipelining", func(t *testing.T) { checkPipelining(t, h) })
+	t.Run("namespaces", func(t *testing.T) { checkNamespaces(t, h) })
+}
+
+// checkHello verifies the server hello parses, advertises base:1.0 and
+// carries a session-id.
+func checkHello(t *testing.T, h *Harness) {
+	c := h.Dial(t)
+	hello, err := server.ParseElement([]byte(c.ServerHello))
+	if err != nil {
+		t.Fatalf("server hello does not parse: %v\n%s", err, c.ServerHello)
+	}
+	if hello.Local() != "hello" {
+		t.Fatalf("server sent <%s>, want <hello>", hello.Local())
+	}
+	if !strings.Contains(c.ServerHello, "urn:ietf:params:netconf:base:1.0") {
+		t.Errorf("server hello does not advertise base:1.0")
+	}
+	if hello.ChildText("session-id") == "" {
+		t.Errorf("server hello has no <session-id>")
+	}
+}
+
+// checkFraming sends the same get through framing variants a tolerant
+// server must accept: an XML declaration prefix and whitespace around
+// the document.
+func checkFraming(t *testing.T, h *Harness) {
+	c := h.Dial(t)
+	rpc := `<rpc message-id="f1" xmlns=%q><get/></rpc>`
+	variants := []string{
+		netconfutil.XMLDeclaration + "\n" + fmt.Sprintf(rpc, netconfutil.BaseNS),
+		"\n  " + fmt.Sprintf(rpc, netconfutil.BaseNS) + "\n",
+	}
+	for i, frame := range variants {
+		if err := c.SendFrame(frame); err != nil {
+			t.Fatalf("variant %d: send: %v", i, err)
+		}
+		reply := mustReadReply(t, c)
+		if reply.Attr("message-id") != "f1" {
+			t.Errorf("variant %d: reply message-id %q, want f1", i, reply.Attr("message-id"))
+		}
+	}
+}
+
+// checkBadXML verifies malformed XML is answered with an rpc-error
+// instead of killing the session.
+func checkBadXML(t *testing.T, h *Harness) {
+	c := h.Dial(t)
+	if err := c.SendFrame(`<rpc message-id="b1"><get>`); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	reply := mustReadReply(t, c)
+	if reply.Child("rpc-error") == nil {
+		t.Fatalf("malformed XML got no rpc-error:\n%s", server.RenderElement(reply))
+	}
+	// The session must survive the parse error.
+	if _, err := c.RPC("<get/>"); err != nil {
+		t.Fatalf("send after parse error: %v", err)
+	}
+	mustReadReply(t, c)
+}
+
+// checkShellGuidance verifies a bare command line (a confused
+// interactive client) gets the guidance error, not a hang.
+func checkShellGuidance(t *testing.T, h *Harness) {
+	c := h.Dial(t)
+	if err := c.SendFrame("help"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	reply := mustReadReply(t, c)
+	errElem := reply.Child("rpc-error")
+	if errElem == nil {
+		t.Fatalf("bare command got no rpc-error:\n%s", server.RenderElement(reply))
+	}
+	if !strings.Contains(errElem.ChildText("error-message"), "NETCONF") {
+		t.Errorf("guidance message does not mention NETCONF: %q", errElem.ChildText("error-message"))
+	}
+}
+
+// checkPipelining writes several RPCs in one burst and verifies the
+// replies come back complete and in request order.
+func checkPipelining(t *testing.T, h *Harness) {
+	c := h.Dial(t)
+	const n = 5
+	var burst strings.Builder
+	for i := 1; i <= n; i++ {
+		burst.WriteString(netconfutil.Frame(fmt.Sprintf(`<rpc message-id="p%d" xmlns=%q><get/></rpc>`, i, netconfutil.BaseNS)))
+	}
+	if err := c.SendRaw(burst.String()); err != nil {
+		t.Fatalf("send burst: %v", err)
+	}
+	for i := 1; i <= n; i++ {
+		reply := mustReadReply(t, c)
+		if want := fmt.Sprintf("p%d", i); reply.Attr("message-id") != want {
+			t.Fatalf("reply %d has message-id %q, want %q", i, reply.Attr("message-id"), want)
+		}
+	}
+}
+
+// checkNamespaces sends a filtered get for every namespace the hello
+// advertises and verifies each yields exactly one well-formed reply —
+// data or a proper rpc-error, never a hang or a malformed frame.
+func checkNamespaces(t *testing.T, h *Harness) {
+	c := h.Dial(t)
+	hello, err := server.ParseElement([]byte(c.ServerHello))
+	if err != nil {
+		t.Fatalf("server hello does not parse: %v", err)
+	}
+	caps := hello.Child("capabilities")
+	if caps == nil {
+		t.Fatalf("server hello has no <capabilities>")
+	}
+	var namespaces []string
+	caps.EachChild("capability", func(e *server.Element) {
+		if ns := strings.TrimSpace(e.Text); strings.HasPrefix(ns, "yang:") {
+			namespaces = append(namespaces, ns)
+		}
+	})
+	if len(namespaces) == 0 {
+		t.Skip("no yang: namespaces registered")
+	}
+	for _, ns := range namespaces {
+		msgID, err := c.RPC(fmt.Sprintf(`<get><filter><state xmlns=%q/></filter></get>`, ns))
+		if err != nil {
+			t.Fatalf("%s: send: %v", ns, err)
+		}
+		reply := mustReadReply(t, c)
+		if reply.Attr("message-id") != msgID {
+			t.Errorf("%s: reply message-id %q, want %q", ns, reply.Attr("message-id"), msgID)
+		}
+		if reply.Child("data") == nil && reply.Child("rpc-error") == nil {
+			t.Errorf("%s: reply carries neither <data> nor <rpc-error>:\n%s", ns, server.RenderElement(reply))
+		}
+	}
+}
+
+// mustReadReply reads one frame and requires it to parse as <rpc-reply>.
+func mustReadReply(t *testing.T, c *Client) *server.Element {
+	t.Helper()
+	frame, err := c.ReadFrame()
+	if err != nil {
+		t.Fatalf("read reply: %v", err)
+	}
+	reply, err := server.ParseElement([]byte(frame))
+	if err != nil {
+		t.Fatalf("reply does not parse: %v\n%s", err, frame)
+	}
+	if reply.Local() != "rpc-reply" {
+		t.Fatalf("got <%s>, want <rpc-reply>:\n%s", reply.Local(), frame)
+	}
+	return reply
+}
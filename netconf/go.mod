@@ -0,0 +1,10 @@
+module github.com/rahulrock213/switch/netconf
+
+go 1.21
+
+require (
+	github.com/gosnmp/gosnmp v1.37.0
+	golang.org/x/crypto v0.17.0
+)
+
+require golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSBanner is the namespace for the CLI login banner and MOTD.
+const NSBanner = "yang:banner"
+
+const (
+	uidBannerLoginSet = "banner.login.set"
+	uidBannerMotdSet  = "banner.motd.set"
+	uidBannerGet      = "banner.get"
+
+	// maxBannerLen bounds banner text; the device CLI truncates
+	// anything longer.
+	maxBannerLen = 2000
+)
+
+func bannerHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSBanner,
+		Get:       getBanner,
+		Edit:      editBanner,
+	}
+}
+
+// editBanner sets the login banner and/or MOTD text. Text is taken
+// verbatim including newlines; an empty element clears that banner.
+func editBanner(ctx *server.Context, item *server.Element) error {
+	login := item.Child("login")
+	motd := item.Child("motd")
+	if login == nil && motd == nil {
+		return server.MissingElement("login or motd")
+	}
+	set := func(uid string, e *server.Element) error {
+		if e == nil {
+			return nil
+		}
+		text := e.Text
+		if len(text) > maxBannerLen {
+			return server.InvalidValue("banner text exceeds %d bytes", maxBannerLen)
+		}
+		_, err := ctx.Miyagi.Call(ctx, uid, map[string]any{"text": text})
+		return err
+	}
+	if err := set(uidBannerLoginSet, login); err != nil {
+		return err
+	}
+	return set(uidBannerMotdSet, motd)
+}
+
+// getBanner returns the configured login banner and MOTD.
+func getBanner(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidBannerGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var banners struct {
+		Login string `json:"login"`
+		Motd  string `json:"motd"`
+	}
+	if err := json.Unmarshal(ret, &banners); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidBannerGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<banner xmlns=%q>`, NSBanner)
+	fmt.Fprintf(&b, "<login>%s</login><motd>%s</motd>",
+		server.EscapeXML(banners.Login), server.EscapeXML(banners.Motd))
+	b.WriteString("</banner>")
+	return b.String(), nil
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSCliExport is the namespace for the CLI export RPC.
+const NSCliExport = "yang:cli_export"
+
+func cliExportHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSCliExport,
+		RPC:       cliExportRPC,
+	}
+}
+
+// cliExportRPC serves <export/>: it reads the running state through the
+// normal get UIDs and renders it as the equivalent CLI commands, so the
+// configuration can be reviewed or pasted into a console.
+func cliExportRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "export" {
+		return "", server.OperationNotSupported("unknown cli_export rpc <%s>", op.Local())
+	}
+	var lines []string
+
+	vlans, err := fetchVlans(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range vlans {
+		lines = append(lines, fmt.Sprintf("vlan %d", v.ID))
+		if v.Name != "" {
+			lines = append(lines, fmt.Sprintf(" name %s", v.Name))
+		}
+	}
+
+	ret, err := ctx.Miyagi.Call(ctx, uidPortGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var ports []miyagiPort
+	if err := json.Unmarshal(ret, &ports); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidPortGet, err)
+	}
+	for _, p := range ports {
+		lines = append(lines, fmt.Sprintf("interface %s", p.Name))
+		if p.Description != "" {
+			lines = append(lines, fmt.Sprintf(" description %s", p.Description))
+		}
+		if p.Speed != "" && p.Speed != "auto" {
+			lines = append(lines, fmt.Sprintf(" speed %s", p.Speed))
+		}
+		if p.AdminStatus == "down" {
+			lines = append(lines, " shutdown")
+		}
+	}
+
+	ret, err = ctx.Miyagi.Call(ctx, uidIPIntfGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var intfs []miyagiIpInterface
+	if err := json.Unmarshal(ret, &intfs); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidIPIntfGet, err)
+	}
+	for _, intf := range intfs {
+		lines = append(lines, fmt.Sprintf("interface %s", intf.Name))
+		for _, a := range intf.Addresses {
+			cmd := fmt.Sprintf(" ip address %s %s", a.IP, a.Mask)
+			if a.Secondary {
+				cmd += " secondary"
+			}
+			lines = append(lines, cmd)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<cli xmlns=%q>`, NSCliExport)
+	for _, line := range lines {
+		fmt.Fprintf(&b, "<line>%s</line>", server.EscapeXML(line))
+	}
+	b.WriteString("</cli>")
+	return b.String(), nil
+}
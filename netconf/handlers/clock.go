@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSClock is the namespace for the system clock, timezone and DST
+// rules, so a controller can time-sync devices that cannot reach an
+// NTP server.
+const NSClock = "yang:clock"
+
+const (
+	uidClockTimeSet     = "clock.time.set"
+	uidClockTimezoneSet = "clock.timezone.set"
+	uidClockDstSet      = "clock.dst.set"
+	uidClockGet         = "clock.get"
+
+	driftPollInterval = 5 * time.Minute
+)
+
+// driftState holds the most recent measured offset between device and
+// gateway time, and whether the drift alarm is currently raised.
+var driftState struct {
+	sync.Mutex
+	measured bool
+	drift    time.Duration
+	raised   bool
+}
+
+func clockHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSClock,
+		Get:       getClock,
+		Edit:      editClock,
+	}
+}
+
+// editClock applies whichever of <time>, <timezone> and <dst> are
+// present. <time> is RFC 3339; <timezone> is an IANA zone name, which
+// is validated locally before being pushed to the device.
+func editClock(ctx *server.Context, item *server.Element) error {
+	applied := false
+	if t := item.ChildText("time"); t != "" {
+		when, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return server.InvalidValue("<time> must be RFC 3339, got %q", t)
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidClockTimeSet, map[string]any{"time": when.UTC().Format(time.RFC3339)}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if tz := item.ChildText("timezone"); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return server.InvalidValue("unknown timezone %q", tz)
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidClockTimezoneSet, map[string]any{"zone": tz}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if dst := item.Child("dst"); dst != nil {
+		arg := map[string]any{
+			"start":  dst.ChildText("start"),
+			"end":    dst.ChildText("end"),
+			"offset": dst.ChildText("offset"),
+		}
+		if arg["start"] == "" || arg["end"] == "" {
+			return server.MissingElement("dst start/end")
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidClockDstSet, arg); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if !applied {
+		return server.MissingElement("time, timezone or dst")
+	}
+	return nil
+}
+
+// getClock returns the current device time, timezone and DST rule.
+func getClock(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidClockGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var clk struct {
+		Time     string `json:"time"`
+		Timezone string `json:"zone"`
+		Dst      struct {
+			Start  string `json:"start"`
+			End    string `json:"end"`
+			Offset string `json:"offset"`
+		} `json:"dst"`
+	}
+	if err := json.Unmarshal(ret, &clk); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidClockGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<clock xmlns=%q>`, NSClock)
+	fmt.Fprintf(&b, "<time>%s</time><timezone>%s</timezone>",
+		server.EscapeXML(clk.Time), server.EscapeXML(clk.Timezone))
+	// The drift watcher only measures the default device; proxied
+	// devices get no drift leaf rather than the default device's value.
+	if ctx.Device == "" {
+		driftState.Lock()
+		if driftState.measured {
+			fmt.Fprintf(&b, "<drift-ms>%d</drift-ms>", driftState.drift.Milliseconds())
+		}
+		driftState.Unlock()
+	}
+	if clk.Dst.Start != "" {
+		fmt.Fprintf(&b, "<dst><start>%s</start><end>%s</end><offset>%s</offset></dst>",
+			server.EscapeXML(clk.Dst.Start), server.EscapeXML(clk.Dst.End), server.EscapeXML(clk.Dst.Offset))
+	}
+	b.WriteString("</clock>")
+	return b.String(), nil
+}
+
+// WatchClockDrift periodically compares device time against gateway
+// time and emits a <clock-drift> notification on the ENVIRONMENT
+// stream when the offset crosses threshold, and again when it
+// recovers. Devices that cannot report their clock are skipped.
+func WatchClockDrift(srv *server.Server, threshold time.Duration) {
+	ctx := srv.BackgroundContext()
+	if threshold <= 0 || !ctx.HasFeature(uidClockGet) {
+		return
+	}
+	ticker := time.NewTicker(driftPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ret, err := ctx.Miyagi.Call(ctx, uidClockGet, nil)
+		if err != nil {
+			continue
+		}
+		var clk struct {
+			Time string `json:"time"`
+		}
+		if err := json.Unmarshal(ret, &clk); err != nil {
+			continue
+		}
+		deviceTime, err := time.Parse(time.RFC3339, clk.Time)
+		if err != nil {
+			continue
+		}
+		drift := time.Since(deviceTime)
+		if drift < 0 {
+			drift = -drift
+		}
+		exceeded := drift > threshold
+		driftState.Lock()
+		was := driftState.raised
+		driftState.measured = true
+		driftState.drift = drift
+		driftState.raised = exceeded
+		driftState.Unlock()
+		if was == exceeded {
+			continue
+		}
+		state := "exceeded"
+		if !exceeded {
+			state = "recovered"
+		}
+		srv.Notify("ENVIRONMENT", fmt.Sprintf(
+			`<clock-drift xmlns=%q><drift-ms>%d</drift-ms><threshold-ms>%d</threshold-ms><state>%s</state></clock-drift>`,
+			NSClock, drift.Milliseconds(), threshold.Milliseconds(), state))
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSConfigLog is the namespace for the configuration change log.
+const NSConfigLog = "yang:config_log"
+
+func configLogHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSConfigLog,
+		RPC:       configLogRPC,
+	}
+}
+
+// configLogRPC serves <get-changes>, optionally with a <since> sequence
+// number: the reply lists every change applied after that point, which
+// is the configuration diff between then and now.
+func configLogRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "get-changes" {
+		return "", server.OperationNotSupported("unknown config_log rpc <%s>", op.Local())
+	}
+	var since uint64
+	if s := op.ChildText("since"); s != "" {
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return "", server.InvalidValue("since must be a sequence number, got %q", s)
+		}
+		since = v
+	}
+	entries := ctx.Server.Changes.Since(since)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<changes xmlns=%q>`, NSConfigLog)
+	for _, e := range entries {
+		b.WriteString("<change>")
+		fmt.Fprintf(&b, "<seq>%d</seq><time>%s</time><session-id>%d</session-id>",
+			e.Seq, e.Time.UTC().Format("2006-01-02T15:04:05Z"), e.SessionID)
+		fmt.Fprintf(&b, "<namespace>%s</namespace>", server.EscapeXML(e.Namespace))
+		b.WriteString(e.Item)
+		b.WriteString("</change>")
+	}
+	b.WriteString("</changes>")
+	return b.String(), nil
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSDhcp is the namespace for DHCP lease and binding state.
+const NSDhcp = "yang:dhcp"
+
+const (
+	uidDhcpLeasesGet   = "dhcp.server.leases.get"
+	uidDhcpBindingsGet = "dhcp.snooping.bindings.get"
+)
+
+func dhcpHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSDhcp,
+		Get:       getDhcp,
+	}
+}
+
+// miyagiDhcpEntry mirrors one lease or snooping binding; both device
+// tables share the same shape.
+type miyagiDhcpEntry struct {
+	IP     string `json:"ip"`
+	Mac    string `json:"mac"`
+	Vlan   int    `json:"vlan"`
+	Ifname string `json:"ifname"`
+	Expiry string `json:"expiry"`
+}
+
+// getDhcp returns the DHCP server lease table and the snooping binding
+// table, skipping whichever the device does not support, so IPAM tools
+// can reconcile address usage in one call.
+func getDhcp(ctx *server.Context, filter *server.Element) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<dhcp xmlns=%q>`, NSDhcp)
+	if ctx.HasFeature(uidDhcpLeasesGet) {
+		if err := dhcpTable(ctx, &b, uidDhcpLeasesGet, "leases", "lease"); err != nil {
+			return "", err
+		}
+	}
+	if ctx.HasFeature(uidDhcpBindingsGet) {
+		if err := dhcpTable(ctx, &b, uidDhcpBindingsGet, "bindings", "binding"); err != nil {
+			return "", err
+		}
+	}
+	b.WriteString("</dhcp>")
+	return b.String(), nil
+}
+
+func dhcpTable(ctx *server.Context, b *strings.Builder, uid, listTag, entryTag string) error {
+	ret, err := ctx.Miyagi.Call(ctx, uid, nil)
+	if err != nil {
+		return err
+	}
+	var entries []miyagiDhcpEntry
+	if err := json.Unmarshal(ret, &entries); err != nil {
+		return server.OperationFailed("decode %s reply: %v", uid, err)
+	}
+	fmt.Fprintf(b, "<%s>", listTag)
+	for _, e := range entries {
+		fmt.Fprintf(b, "<%s>", entryTag)
+		fmt.Fprintf(b, "<ip>%s</ip><mac>%s</mac><vlan>%d</vlan><interface>%s</interface><expiry>%s</expiry>",
+			server.EscapeXML(e.IP), server.EscapeXML(e.Mac), e.Vlan,
+			server.EscapeXML(e.Ifname), server.EscapeXML(e.Expiry))
+		fmt.Fprintf(b, "</%s>", entryTag)
+	}
+	fmt.Fprintf(b, "</%s>", listTag)
+	return nil
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSDrift is the namespace for configuration drift detection: it
+// compares the state the device reports now against the intent the
+// gateway last applied, so out-of-band changes (CLI, web UI, another
+// controller) are surfaced instead of silently diverging.
+const NSDrift = "yang:drift"
+
+func driftHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSDrift,
+		RPC:       driftRPC,
+	}
+}
+
+// driftEntry is one intent item whose device state no longer matches.
+type driftEntry struct {
+	Namespace string
+	Key       string
+	// State is "missing" when the object's key is gone from the
+	// device, or "modified" when the object exists but some leaves
+	// differ from the stored intent.
+	State string
+	// Leaves names the intent leaves not reflected in device state.
+	Leaves []string
+}
+
+// computeDrift re-reads device state through the registered get
+// handlers and checks every persisted intent item against it. The
+// comparison is leaf containment, not schema diffing: an intent leaf
+// drifts when its value no longer appears in the namespace's state
+// subtree. Namespaces without a get handler cannot be verified and are
+// skipped.
+func computeDrift(ctx *server.Context) ([]driftEntry, error) {
+	if ctx.Server.Intent == nil {
+		return nil, server.OperationNotSupported("drift detection requires the intent store; start with -intent")
+	}
+	state := make(map[string]string)
+	var drift []driftEntry
+	for _, it := range ctx.Server.Intent.Items() {
+		h := ctx.Server.Handler(it.Namespace)
+		if h == nil || h.Get == nil {
+			continue
+		}
+		if _, ok := state[it.Namespace]; !ok {
+			part, err := h.Get(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			state[it.Namespace] = part
+		}
+		item, err := server.ParseElement([]byte(it.XML))
+		if err != nil {
+			continue
+		}
+		if e := checkItem(item, it, state[it.Namespace]); e != nil {
+			drift = append(drift, *e)
+		}
+	}
+	return drift, nil
+}
+
+// checkItem compares one intent item against its namespace's state
+// subtree, returning nil when the item is fully reflected.
+func checkItem(item *server.Element, it server.IntentItem, state string) *driftEntry {
+	// The natural key must be present at all; otherwise the object
+	// was removed behind the gateway's back.
+	for _, id := range []string{"name", "id", "prefix", "ifname"} {
+		v := item.ChildText(id)
+		if v == "" {
+			continue
+		}
+		if !containsLeaf(state, id, v) {
+			return &driftEntry{Namespace: it.Namespace, Key: it.Key, State: "missing"}
+		}
+		break
+	}
+	var stale []string
+	for i := range item.Children {
+		c := &item.Children[i]
+		// Only plain leaves are comparable against state output;
+		// "operation" is edit-config metadata, not device state.
+		if len(c.Children) > 0 || c.Local() == "operation" {
+			continue
+		}
+		v := strings.TrimSpace(c.Text)
+		if v == "" {
+			continue
+		}
+		if !containsLeaf(state, c.Local(), v) {
+			stale = append(stale, c.Local())
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return &driftEntry{Namespace: it.Namespace, Key: it.Key, State: "modified", Leaves: stale}
+}
+
+// containsLeaf reports whether the state subtree carries the leaf with
+// exactly the given value.
+func containsLeaf(state, local, value string) bool {
+	return strings.Contains(state, fmt.Sprintf("<%s>%s</%s>", local, server.EscapeXML(value), local))
+}
+
+// driftRPC serves <get-drift>, returning every intent item whose
+// device state has diverged.
+func driftRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "get-drift" {
+		return "", server.OperationNotSupported("unknown RPC <%s> in %s", op.Local(), NSDrift)
+	}
+	drift, err := computeDrift(ctx)
+	if err != nil {
+		return "", err
+	}
+	return renderDrift(drift), nil
+}
+
+// renderDrift builds the <drift> payload shared by the RPC reply and
+// the drift notification.
+func renderDrift(drift []driftEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<drift xmlns=%q>`, NSDrift)
+	for _, d := range drift {
+		fmt.Fprintf(&b, "<item><namespace>%s</namespace><key>%s</key><state>%s</state>",
+			server.EscapeXML(d.Namespace), server.EscapeXML(d.Key), d.State)
+		for _, leaf := range d.Leaves {
+			fmt.Fprintf(&b, "<leaf>%s</leaf>", server.EscapeXML(leaf))
+		}
+		b.WriteString("</item>")
+	}
+	b.WriteString("</drift>")
+	return b.String()
+}
+
+// WatchConfigDrift periodically reconciles device state against the
+// persisted intent and emits the drift report on the CONFIG stream
+// whenever it changes — including once when drift clears, so consumers
+// see the recovery too. A nil intent store disables the watcher.
+func WatchConfigDrift(srv *server.Server, interval time.Duration) {
+	if srv.Intent == nil || interval <= 0 {
+		return
+	}
+	ctx := srv.BackgroundContext()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	lastReport := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		drift, err := computeDrift(ctx)
+		if err != nil {
+			continue
+		}
+		report := ""
+		if len(drift) > 0 {
+			report = renderDrift(drift)
+		}
+		if report == lastReport {
+			continue
+		}
+		if report == "" {
+			srv.Notify("CONFIG", fmt.Sprintf(`<drift xmlns=%q/>`, NSDrift))
+		} else {
+			srv.Notify("CONFIG", report)
+		}
+		lastReport = report
+	}
+}
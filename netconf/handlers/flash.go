@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSFlash is the namespace for switch flash file housekeeping (image
+// and config files ahead of upgrades).
+const NSFlash = "yang:flash"
+
+const (
+	uidFlashFilesGet   = "flash.files.get"
+	uidFlashFileDelete = "flash.file.delete"
+	uidFlashFileCopy   = "flash.file.copy"
+)
+
+func flashHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSFlash,
+		RPC:       flashRPC,
+	}
+}
+
+// miyagiFlashFile mirrors one entry of the flash.files.get return
+// payload.
+type miyagiFlashFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Date string `json:"date"`
+}
+
+// flashRPC serves the flash file RPCs: <list/>, <delete><name> and
+// <copy><source><destination>. Copy endpoints are either a bare flash
+// file name or a tftp:// / scp:// URL; the transfer itself runs on the
+// device.
+func flashRPC(ctx *server.Context, op *server.Element) (string, error) {
+	switch op.Local() {
+	case "list":
+		ret, err := ctx.Miyagi.Call(ctx, uidFlashFilesGet, nil)
+		if err != nil {
+			return "", err
+		}
+		var files []miyagiFlashFile
+		if err := json.Unmarshal(ret, &files); err != nil {
+			return "", server.OperationFailed("decode %s reply: %v", uidFlashFilesGet, err)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, `<files xmlns=%q>`, NSFlash)
+		for _, f := range files {
+			fmt.Fprintf(&b, "<file><name>%s</name><size>%d</size><date>%s</date></file>",
+				server.EscapeXML(f.Name), f.Size, server.EscapeXML(f.Date))
+		}
+		b.WriteString("</files>")
+		return b.String(), nil
+	case "delete":
+		name := op.ChildText("name")
+		if err := validFlashName(name); err != nil {
+			return "", err
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidFlashFileDelete, map[string]any{"name": name}); err != nil {
+			return "", err
+		}
+		return "<ok/>", nil
+	case "copy":
+		src := op.ChildText("source")
+		dst := op.ChildText("destination")
+		if src == "" || dst == "" {
+			return "", server.MissingElement("source and destination")
+		}
+		if err := validCopyEndpoint(src); err != nil {
+			return "", err
+		}
+		if err := validCopyEndpoint(dst); err != nil {
+			return "", err
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidFlashFileCopy, map[string]any{"source": src, "destination": dst}); err != nil {
+			return "", err
+		}
+		return "<ok/>", nil
+	default:
+		return "", server.OperationNotSupported("unknown flash rpc <%s>", op.Local())
+	}
+}
+
+// validFlashName rejects empty names and anything resembling a path,
+// since flash is flat and traversal must not reach the host filesystem.
+func validFlashName(name string) error {
+	if name == "" {
+		return server.MissingElement("name")
+	}
+	if strings.ContainsAny(name, "/\\") || strings.Contains(name, "..") {
+		return server.InvalidValue("%q is not a flash file name", name)
+	}
+	return nil
+}
+
+// validCopyEndpoint accepts a flash file name or a tftp:// / scp:// URL.
+func validCopyEndpoint(ep string) error {
+	if strings.HasPrefix(ep, "tftp://") || strings.HasPrefix(ep, "scp://") {
+		return nil
+	}
+	return validFlashName(ep)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSGreenEthernet is the namespace for green-ethernet power saving.
+const NSGreenEthernet = "yang:green_ethernet"
+
+const (
+	uidGreenShortReachSet   = "green.short_reach.set"
+	uidGreenEnergyDetectSet = "green.energy_detect.set"
+	uidGreenGet             = "green.get"
+)
+
+func greenEthernetHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSGreenEthernet,
+		Get:       getGreenEthernet,
+		Edit:      editGreenEthernet,
+	}
+}
+
+// editGreenEthernet sets short-reach and energy-detect globally or,
+// when the item carries interface addressing, for one port.
+func editGreenEthernet(ctx *server.Context, item *server.Element) error {
+	arg := map[string]any{}
+	if item.Child("name") != nil || item.Child("ifindex") != nil {
+		name, err := InterfaceRef(ctx, item)
+		if err != nil {
+			return err
+		}
+		arg["ifname"] = name
+	}
+	applied := false
+	for tag, uid := range map[string]string{
+		"short-reach":   uidGreenShortReachSet,
+		"energy-detect": uidGreenEnergyDetectSet,
+	} {
+		c := item.Child(tag)
+		if c == nil {
+			continue
+		}
+		a := map[string]any{"enable": strings.TrimSpace(c.Text) == "true"}
+		for k, v := range arg {
+			a[k] = v
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uid, a); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if !applied {
+		return server.MissingElement("short-reach or energy-detect")
+	}
+	return nil
+}
+
+// miyagiGreen mirrors the green.get return payload.
+type miyagiGreen struct {
+	ShortReach     bool   `json:"short_reach"`
+	EnergyDetect   bool   `json:"energy_detect"`
+	SavingsMilliW  int    `json:"savings_mw"`
+	CumulativeKWh  string `json:"cumulative_kwh"`
+	PortShortReach []struct {
+		Ifname  string `json:"ifname"`
+		Enabled bool   `json:"enabled"`
+		Savings int    `json:"savings_mw"`
+	} `json:"ports"`
+}
+
+// getGreenEthernet returns the global settings, the estimated power
+// savings, and the per-port state.
+func getGreenEthernet(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidGreenGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var g miyagiGreen
+	if err := json.Unmarshal(ret, &g); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidGreenGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<green-ethernet xmlns=%q>`, NSGreenEthernet)
+	fmt.Fprintf(&b, "<short-reach>%t</short-reach><energy-detect>%t</energy-detect>", g.ShortReach, g.EnergyDetect)
+	fmt.Fprintf(&b, "<power-savings-mw>%d</power-savings-mw>", g.SavingsMilliW)
+	if g.CumulativeKWh != "" {
+		fmt.Fprintf(&b, "<cumulative-kwh>%s</cumulative-kwh>", server.EscapeXML(g.CumulativeKWh))
+	}
+	for _, p := range g.PortShortReach {
+		fmt.Fprintf(&b, "<port><name>%s</name><enabled>%t</enabled><power-savings-mw>%d</power-savings-mw></port>",
+			server.EscapeXML(p.Ifname), p.Enabled, p.Savings)
+	}
+	b.WriteString("</green-ethernet>")
+	return b.String(), nil
+}
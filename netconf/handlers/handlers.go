@@ -0,0 +1,43 @@
+// Package handlers contains the per-namespace NETCONF handlers that
+// translate configuration and state requests into Miyagi API calls.
+package handlers
+
+import "github.com/rahulrock213/switch/netconf/server"
+
+// RegisterAll wires every namespace handler into srv.
+func RegisterAll(srv *server.Server) {
+	srv.Register(ipInterfaceHandler())
+	srv.Register(oobHandler())
+	srv.Register(routeHandler())
+	srv.Register(vlanHandler())
+	srv.Register(vlanMembersHandler())
+	srv.Register(portChannelHandler())
+	srv.Register(portHandler())
+	srv.Register(portDescriptionHandler())
+	srv.Register(configLogHandler())
+	srv.Register(snapshotHandler())
+	srv.Register(scheduleHandler())
+	srv.Register(templateHandler())
+	srv.Register(cliExportHandler())
+	srv.Register(stackHandler())
+	srv.Register(snmpHandler())
+	srv.Register(cliHandler())
+	srv.Register(macHandler())
+	srv.Register(topologyHandler())
+	srv.Register(dhcpHandler())
+	srv.Register(igmpHandler())
+	srv.Register(stpStateHandler())
+	srv.Register(stpHandler())
+	srv.Register(bannerHandler())
+	srv.Register(clockHandler())
+	srv.Register(flashHandler())
+	srv.Register(greenEthernetHandler())
+	srv.Register(transceiverHandler())
+	srv.Register(mirrorHandler())
+	srv.Register(qosHandler())
+	srv.Register(switchportHandler())
+	srv.Register(interfaceRatesHandler())
+	srv.Register(driftHandler())
+	srv.Register(schemaHandler())
+	srv.Register(refreshHandler())
+}
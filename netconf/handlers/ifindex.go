@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+const uidIfIndexGet = "interface.ifindex.get"
+
+// ifIndexCache caches the interface name <-> ifIndex table, one table
+// per proxied device so lookups never resolve against another device's
+// interfaces. A table only changes when ports or LAGs are created, so
+// it is refreshed lazily on a lookup miss.
+var ifIndexCache struct {
+	sync.Mutex
+	byDevice map[string]*ifIndexTable
+}
+
+// ifIndexTable is one device's name <-> ifIndex mapping.
+type ifIndexTable struct {
+	byName  map[string]int
+	byIndex map[int]string
+}
+
+func refreshIfIndexCache(ctx *server.Context) (*ifIndexTable, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidIfIndexGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Name    string `json:"ifname"`
+		IfIndex int    `json:"ifindex"`
+	}
+	if err := json.Unmarshal(ret, &entries); err != nil {
+		return nil, server.OperationFailed("decode %s reply: %v", uidIfIndexGet, err)
+	}
+	table := &ifIndexTable{
+		byName:  make(map[string]int, len(entries)),
+		byIndex: make(map[int]string, len(entries)),
+	}
+	for _, e := range entries {
+		table.byName[e.Name] = e.IfIndex
+		table.byIndex[e.IfIndex] = e.Name
+	}
+	if ifIndexCache.byDevice == nil {
+		ifIndexCache.byDevice = make(map[string]*ifIndexTable)
+	}
+	ifIndexCache.byDevice[ctx.Device] = table
+	return table, nil
+}
+
+// IfIndexToName resolves an ifIndex to the interface name on the
+// request's device.
+func IfIndexToName(ctx *server.Context, ifIndex int) (string, error) {
+	ifIndexCache.Lock()
+	defer ifIndexCache.Unlock()
+	if table := ifIndexCache.byDevice[ctx.Device]; table != nil {
+		if name, ok := table.byIndex[ifIndex]; ok {
+			return name, nil
+		}
+	}
+	table, err := refreshIfIndexCache(ctx)
+	if err != nil {
+		return "", err
+	}
+	name, ok := table.byIndex[ifIndex]
+	if !ok {
+		return "", server.InvalidValue("unknown ifindex %d", ifIndex)
+	}
+	return name, nil
+}
+
+// NameToIfIndex resolves an interface name to its ifIndex on the
+// request's device.
+func NameToIfIndex(ctx *server.Context, name string) (int, error) {
+	ifIndexCache.Lock()
+	defer ifIndexCache.Unlock()
+	if table := ifIndexCache.byDevice[ctx.Device]; table != nil {
+		if idx, ok := table.byName[name]; ok {
+			return idx, nil
+		}
+	}
+	table, err := refreshIfIndexCache(ctx)
+	if err != nil {
+		return 0, err
+	}
+	idx, ok := table.byName[name]
+	if !ok {
+		return 0, server.InvalidValue("unknown interface %q", name)
+	}
+	return idx, nil
+}
+
+// InterfaceRef resolves the interface addressed by a config item, which
+// may carry either a <name> or an <ifindex> child (dual addressing).
+func InterfaceRef(ctx *server.Context, item *server.Element) (string, error) {
+	if name := item.ChildText("name"); name != "" {
+		return name, nil
+	}
+	if s := item.ChildText("ifindex"); s != "" {
+		idx, err := strconv.Atoi(s)
+		if err != nil {
+			return "", server.InvalidValue("ifindex %q is not a number", s)
+		}
+		return IfIndexToName(ctx, idx)
+	}
+	return "", server.MissingElement("name")
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSGetInterfaceRates is the namespace for computed interface rates,
+// so NMSes get bits/packets per second directly instead of doing
+// delta math on raw octet counters.
+const NSGetInterfaceRates = "yang:get_interface_rates"
+
+const uidIntfCountersGet = "interface.counters.get"
+
+func interfaceRatesHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSGetInterfaceRates,
+		Get:       getInterfaceRates,
+	}
+}
+
+// miyagiIntfCounters mirrors one entry of the interface.counters.get
+// return payload.
+type miyagiIntfCounters struct {
+	Ifname    string `json:"ifname"`
+	InOctets  uint64 `json:"in_octets"`
+	OutOctets uint64 `json:"out_octets"`
+	InPkts    uint64 `json:"in_pkts"`
+	OutPkts   uint64 `json:"out_pkts"`
+}
+
+// ifRate is one interface's computed rates over the sampling window.
+type ifRate struct {
+	InBps  uint64
+	OutBps uint64
+	InPps  uint64
+	OutPps uint64
+}
+
+// rateState holds the previous counter sample and the rates computed
+// from the last two samples.
+var rateState struct {
+	sync.Mutex
+	window time.Duration
+	last   map[string]miyagiIntfCounters
+	lastAt time.Time
+	rates  map[string]ifRate
+}
+
+// WatchInterfaceRates samples the interface counters every window and
+// keeps per-interface bit and packet rates for the
+// yang:get_interface_rates handler. A window of zero (or less)
+// disables the watcher.
+func WatchInterfaceRates(srv *server.Server, window time.Duration) {
+	ctx := srv.BackgroundContext()
+	if window <= 0 || !ctx.HasFeature(uidIntfCountersGet) {
+		return
+	}
+	rateState.Lock()
+	rateState.window = window
+	rateState.Unlock()
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ret, err := ctx.Miyagi.Call(ctx, uidIntfCountersGet, nil)
+		if err != nil {
+			continue
+		}
+		var counters []miyagiIntfCounters
+		if err := json.Unmarshal(ret, &counters); err != nil {
+			continue
+		}
+		now := time.Now()
+		rateState.Lock()
+		elapsed := now.Sub(rateState.lastAt).Seconds()
+		cur := make(map[string]miyagiIntfCounters, len(counters))
+		rates := make(map[string]ifRate, len(counters))
+		for _, c := range counters {
+			cur[c.Ifname] = c
+			prev, ok := rateState.last[c.Ifname]
+			// Skip the first sample and counter wraps/resets.
+			if !ok || elapsed <= 0 || c.InOctets < prev.InOctets || c.OutOctets < prev.OutOctets {
+				continue
+			}
+			rates[c.Ifname] = ifRate{
+				InBps:  uint64(float64(c.InOctets-prev.InOctets) * 8 / elapsed),
+				OutBps: uint64(float64(c.OutOctets-prev.OutOctets) * 8 / elapsed),
+				InPps:  uint64(float64(c.InPkts-prev.InPkts) / elapsed),
+				OutPps: uint64(float64(c.OutPkts-prev.OutPkts) / elapsed),
+			}
+		}
+		rateState.last = cur
+		rateState.lastAt = now
+		rateState.rates = rates
+		rateState.Unlock()
+	}
+}
+
+// getInterfaceRates returns the most recent computed rates along with
+// the window they were averaged over. The watcher only samples the
+// default device, so device-addressed requests are rejected rather
+// than answered with another device's rates.
+func getInterfaceRates(ctx *server.Context, filter *server.Element) (string, error) {
+	if ctx.Device != "" {
+		return "", server.OperationNotSupported("interface rates are sampled on the default device only")
+	}
+	rateState.Lock()
+	defer rateState.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<interface-rates xmlns=%q>`, NSGetInterfaceRates)
+	if rateState.window > 0 {
+		fmt.Fprintf(&b, "<window>%s</window>", rateState.window)
+	}
+	names := make([]string, 0, len(rateState.rates))
+	for name := range rateState.rates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r := rateState.rates[name]
+		fmt.Fprintf(&b, "<interface><name>%s</name><in-bps>%d</in-bps><out-bps>%d</out-bps><in-pps>%d</in-pps><out-pps>%d</out-pps></interface>",
+			server.EscapeXML(name), r.InBps, r.OutBps, r.InPps, r.OutPps)
+	}
+	b.WriteString("</interface-rates>")
+	return b.String(), nil
+}
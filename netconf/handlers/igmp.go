@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSIgmp is the namespace for IGMP snooping state.
+const NSIgmp = "yang:igmp"
+
+const uidIgmpGroupsGet = "igmp.snooping.groups.get"
+
+func igmpHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSIgmp,
+		Get:       getIgmpGroups,
+	}
+}
+
+// miyagiIgmpGroup mirrors one entry of the igmp.snooping.groups.get
+// return payload.
+type miyagiIgmpGroup struct {
+	Vlan    int      `json:"vlan"`
+	Group   string   `json:"group"`
+	Ports   []string `json:"ports"`
+	Version int      `json:"version"`
+	Expiry  string   `json:"expiry"`
+}
+
+// getIgmpGroups returns the currently joined multicast groups learned
+// by snooping, grouped per VLAN with their member ports.
+func getIgmpGroups(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidIgmpGroupsGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var groups []miyagiIgmpGroup
+	if err := json.Unmarshal(ret, &groups); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidIgmpGroupsGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<igmp-groups xmlns=%q>`, NSIgmp)
+	for _, g := range groups {
+		b.WriteString("<group>")
+		fmt.Fprintf(&b, "<vlan>%d</vlan><address>%s</address>", g.Vlan, server.EscapeXML(g.Group))
+		if g.Version != 0 {
+			fmt.Fprintf(&b, "<version>%d</version>", g.Version)
+		}
+		for _, p := range g.Ports {
+			fmt.Fprintf(&b, "<port>%s</port>", server.EscapeXML(p))
+		}
+		if g.Expiry != "" {
+			fmt.Fprintf(&b, "<expiry>%s</expiry>", server.EscapeXML(g.Expiry))
+		}
+		b.WriteString("</group>")
+	}
+	b.WriteString("</igmp-groups>")
+	return b.String(), nil
+}
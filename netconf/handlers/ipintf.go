@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSIpInterface is the namespace for L3 interface configuration.
+const NSIpInterface = "yang:ip_interface"
+
+// Miyagi UIDs for the IP interface API. Primary and secondary addresses
+// are managed through different UIDs on the device.
+const (
+	uidIPIntfAddrSet      = "ip.interface.address.set"
+	uidIPIntfAddrDel      = "ip.interface.address.delete"
+	uidIPIntfSecondaryAdd = "ip.interface.secondary.add"
+	uidIPIntfSecondaryDel = "ip.interface.secondary.delete"
+	uidIPIntfMtuSet       = "ip.interface.mtu.set"
+	uidIPIntfIpMtuSet     = "ip.interface.ip_mtu.set"
+	uidIPIntfGet          = "ip.interface.get"
+	uidJumboFramesGet     = "system.jumbo_frames.get"
+)
+
+// MTU limits: the lower bound is the IPv4 minimum, the upper bound the
+// largest jumbo frame the switching silicon handles.
+const (
+	minIntfMtu     = 68
+	maxIntfMtu     = 9216
+	standardMaxMtu = 1518
+)
+
+// IpAddressData is one address assigned to an interface.
+type IpAddressData struct {
+	IP        string
+	Mask      string
+	Secondary bool
+	Delete    bool // remove instead of add
+}
+
+// IpInterfaceData is the parsed form of one <interface> config item.
+// MTU fields are zero when absent.
+type IpInterfaceData struct {
+	Name      string
+	Addresses []IpAddressData
+	MTU       int // L2 MTU on the SVI/loopback
+	IPMTU     int // MTU used for IP fragmentation decisions
+}
+
+func ipInterfaceHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSIpInterface,
+		Get:       getIpInterfaces,
+		Edit:      editIpInterface,
+	}
+}
+
+// ParseIpInterface extracts IpInterfaceData from an <interface> item.
+func ParseIpInterface(item *server.Element) (*IpInterfaceData, error) {
+	data := &IpInterfaceData{Name: item.ChildText("name")}
+	if data.Name == "" {
+		return nil, server.MissingElement("name")
+	}
+	var parseErr error
+	item.EachChild("address", func(addr *server.Element) {
+		a := IpAddressData{
+			IP:        addr.ChildText("ip"),
+			Mask:      addr.ChildText("mask"),
+			Secondary: addr.ChildText("secondary") == "true",
+			Delete:    addr.Attr("operation") == "delete" || addr.ChildText("operation") == "delete",
+		}
+		if a.IP == "" || a.Mask == "" {
+			parseErr = server.InvalidValue("address on %s needs both <ip> and <mask>", data.Name)
+			return
+		}
+		if err := checkAddress("/interface/address/ip", a.IP); err != nil {
+			parseErr = err
+			return
+		}
+		if err := checkMask("/interface/address/mask", a.Mask); err != nil {
+			parseErr = err
+			return
+		}
+		data.Addresses = append(data.Addresses, a)
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	for _, f := range []struct {
+		tag string
+		dst *int
+	}{{"mtu", &data.MTU}, {"ip-mtu", &data.IPMTU}} {
+		s := item.ChildText(f.tag)
+		if s == "" {
+			continue
+		}
+		v, err := strconv.Atoi(s)
+		if err != nil || v < minIntfMtu || v > maxIntfMtu {
+			return nil, server.InvalidValue("%s: <%s> must be %d..%d, got %q", data.Name, f.tag, minIntfMtu, maxIntfMtu, s)
+		}
+		*f.dst = v
+	}
+	if (data.MTU != 0 || data.IPMTU != 0) && !isLogicalInterface(data.Name) {
+		return nil, server.InvalidValue("%s: mtu is configurable on vlan and loopback interfaces only", data.Name)
+	}
+	if data.IPMTU != 0 && data.MTU != 0 && data.IPMTU > data.MTU {
+		return nil, server.InvalidValue("%s: ip-mtu %d cannot exceed mtu %d", data.Name, data.IPMTU, data.MTU)
+	}
+	return data, nil
+}
+
+// isLogicalInterface reports whether name is an SVI or loopback, the
+// interface kinds that carry their own L3 MTU.
+func isLogicalInterface(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasPrefix(lower, "vlan") || strings.HasPrefix(lower, "loopback")
+}
+
+// editIpInterface applies one <interface> item. Each address maps to
+// its own Miyagi call; secondary addresses use the dedicated
+// secondary-address UIDs.
+func editIpInterface(ctx *server.Context, item *server.Element) error {
+	data, err := ParseIpInterface(item)
+	if err != nil {
+		return err
+	}
+	for _, a := range data.Addresses {
+		uid := uidIPIntfAddrSet
+		switch {
+		case a.Secondary && a.Delete:
+			uid = uidIPIntfSecondaryDel
+		case a.Secondary:
+			uid = uidIPIntfSecondaryAdd
+		case a.Delete:
+			uid = uidIPIntfAddrDel
+		}
+		if err := ctx.RequireFeature(uid); err != nil {
+			return err
+		}
+		arg := map[string]any{
+			"ifname": data.Name,
+			"ip":     a.IP,
+			"mask":   a.Mask,
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uid, arg); err != nil {
+			return err
+		}
+	}
+	if data.MTU > standardMaxMtu || data.IPMTU > standardMaxMtu {
+		if err := checkJumboFrames(ctx, data); err != nil {
+			return err
+		}
+	}
+	if data.MTU != 0 {
+		if _, err := ctx.Miyagi.Call(ctx, uidIPIntfMtuSet, map[string]any{"ifname": data.Name, "mtu": data.MTU}); err != nil {
+			return err
+		}
+	}
+	if data.IPMTU != 0 {
+		if _, err := ctx.Miyagi.Call(ctx, uidIPIntfIpMtuSet, map[string]any{"ifname": data.Name, "mtu": data.IPMTU}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkJumboFrames rejects an MTU above the standard frame size when
+// the physical ports are not in jumbo-frame mode, since such packets
+// would be dropped at the first egress anyway.
+func checkJumboFrames(ctx *server.Context, data *IpInterfaceData) error {
+	if !ctx.HasFeature(uidJumboFramesGet) {
+		return nil
+	}
+	ret, err := ctx.Miyagi.Call(ctx, uidJumboFramesGet, nil)
+	if err != nil {
+		return err
+	}
+	var jumbo struct {
+		Enabled bool `json:"enabled"`
+		MaxMtu  int  `json:"max_mtu"`
+	}
+	if err := json.Unmarshal(ret, &jumbo); err != nil {
+		return server.OperationFailed("decode %s reply: %v", uidJumboFramesGet, err)
+	}
+	if !jumbo.Enabled {
+		return server.InvalidValue("%s: mtu above %d requires jumbo frames to be enabled", data.Name, standardMaxMtu)
+	}
+	if jumbo.MaxMtu != 0 && (data.MTU > jumbo.MaxMtu || data.IPMTU > jumbo.MaxMtu) {
+		return server.InvalidValue("%s: mtu exceeds the physical jumbo-frame limit %d", data.Name, jumbo.MaxMtu)
+	}
+	return nil
+}
+
+// miyagiIpInterface mirrors the ip.interface.get return payload.
+type miyagiIpInterface struct {
+	Name      string `json:"ifname"`
+	Addresses []struct {
+		IP        string `json:"ip"`
+		Mask      string `json:"mask"`
+		Secondary bool   `json:"secondary"`
+	} `json:"addresses"`
+	MTU   int `json:"mtu"`
+	IPMTU int `json:"ip_mtu"`
+}
+
+// getIpInterfaces builds the interface list, including every secondary
+// address with its flag.
+func getIpInterfaces(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidIPIntfGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var intfs []miyagiIpInterface
+	if err := json.Unmarshal(ret, &intfs); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidIPIntfGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<interfaces xmlns=%q>`, NSIpInterface)
+	for _, intf := range intfs {
+		b.WriteString("<interface>")
+		fmt.Fprintf(&b, "<name>%s</name>", server.EscapeXML(intf.Name))
+		for _, a := range intf.Addresses {
+			fmt.Fprintf(&b, "<address><ip>%s</ip><mask>%s</mask><secondary>%t</secondary></address>",
+				server.EscapeXML(a.IP), server.EscapeXML(a.Mask), a.Secondary)
+		}
+		if intf.MTU != 0 {
+			fmt.Fprintf(&b, "<mtu>%d</mtu>", intf.MTU)
+		}
+		if intf.IPMTU != 0 {
+			fmt.Fprintf(&b, "<ip-mtu>%d</ip-mtu>", intf.IPMTU)
+		}
+		b.WriteString("</interface>")
+	}
+	b.WriteString("</interfaces>")
+	return b.String(), nil
+}
@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSPortChannel is the namespace for link aggregation groups.
+const NSPortChannel = "yang:port_channel"
+
+const (
+	uidLagGet             = "lag.get"
+	uidLagMemberAdd       = "lag.member.add"
+	uidLagMemberDelete    = "lag.member.delete"
+	uidLacpSysPrioSet     = "lacp.system_priority.set"
+	uidLacpPortPrioSet    = "lacp.port_priority.set"
+	uidLacpPortTimeoutSet = "lacp.port_timeout.set"
+	maxLacpPriority       = 65535
+)
+
+// miyagiLag mirrors one entry of the lag.get return payload.
+type miyagiLag struct {
+	ID         int    `json:"id"`
+	OperStatus string `json:"oper_status"` // "up" or "down"
+	Speed      string `json:"speed"`
+	Members    []struct {
+		Name     string `json:"ifname"`
+		Status   string `json:"status"` // "active", "standby" or "down"
+		Priority int    `json:"lacp_priority"`
+		Timeout  string `json:"lacp_timeout"` // "fast" or "slow"
+	} `json:"members"`
+	SystemPriority int `json:"lacp_system_priority"`
+}
+
+func portChannelHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSPortChannel,
+		Get:       getPortChannels,
+		Edit:      editLacp,
+	}
+}
+
+// editLacp applies LACP tuning: a global <system-priority>, and
+// per-member <member> items carrying <port-priority> and/or <timeout>.
+func editLacp(ctx *server.Context, item *server.Element) error {
+	applied := false
+	if s := item.ChildText("system-priority"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > maxLacpPriority {
+			return server.InvalidValue("lacp system-priority must be 1..%d, got %q", maxLacpPriority, s)
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidLacpSysPrioSet, map[string]any{"priority": v}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	var memberErr error
+	item.EachChild("member", func(m *server.Element) {
+		if memberErr != nil {
+			return
+		}
+		memberErr = editLacpMember(ctx, m)
+		applied = true
+	})
+	if memberErr != nil {
+		return memberErr
+	}
+	if !applied {
+		return server.MissingElement("system-priority or member")
+	}
+	return nil
+}
+
+func editLacpMember(ctx *server.Context, m *server.Element) error {
+	name, err := InterfaceRef(ctx, m)
+	if err != nil {
+		return err
+	}
+	applied := false
+	if lag := m.ChildText("lag"); lag != "" {
+		id, err := strconv.Atoi(lag)
+		if err != nil || id < 1 {
+			return server.InvalidValue("member %s: <lag> must be a positive id, got %q", name, lag)
+		}
+		if m.Attr("operation") == "delete" || m.ChildText("operation") == "delete" {
+			if _, err := ctx.Miyagi.Call(ctx, uidLagMemberDelete, map[string]any{"id": id, "ifname": name}); err != nil {
+				return err
+			}
+		} else {
+			if err := validateLagMember(ctx, id, name); err != nil {
+				return err
+			}
+			if _, err := ctx.Miyagi.Call(ctx, uidLagMemberAdd, map[string]any{"id": id, "ifname": name}); err != nil {
+				return err
+			}
+		}
+		applied = true
+	}
+	if s := m.ChildText("port-priority"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > maxLacpPriority {
+			return server.InvalidValue("member %s: port-priority must be 1..%d, got %q", name, maxLacpPriority, s)
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidLacpPortPrioSet, map[string]any{"ifname": name, "priority": v}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if t := m.ChildText("timeout"); t != "" {
+		if t != "fast" && t != "slow" {
+			return server.InvalidValue("member %s: timeout must be fast or slow, got %q", name, t)
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidLacpPortTimeoutSet, map[string]any{"ifname": name, "timeout": t}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if !applied {
+		return server.MissingElement("lag, port-priority or timeout")
+	}
+	return nil
+}
+
+// validateLagMember checks that the candidate port's speed, duplex and
+// switchport mode match the port-channel's existing members before the
+// join is sent, so the operator gets a descriptive error instead of
+// the device's cryptic rejection. The first existing member is the
+// reference; a port-channel without members accepts anything.
+func validateLagMember(ctx *server.Context, id int, name string) error {
+	ret, err := ctx.Miyagi.Call(ctx, uidLagGet, nil)
+	if err != nil {
+		return err
+	}
+	var lags []miyagiLag
+	if err := json.Unmarshal(ret, &lags); err != nil {
+		return server.OperationFailed("decode %s reply: %v", uidLagGet, err)
+	}
+	var reference string
+	for _, lag := range lags {
+		if lag.ID != id {
+			continue
+		}
+		for _, m := range lag.Members {
+			if m.Name == name {
+				// Re-adding an existing member is a no-op downstream.
+				return nil
+			}
+		}
+		if len(lag.Members) > 0 {
+			reference = lag.Members[0].Name
+		}
+	}
+	if reference == "" {
+		return nil
+	}
+	ports, err := fetchPortTable(ctx)
+	if err != nil {
+		return err
+	}
+	cand, ok := ports[name]
+	ref, refOK := ports[reference]
+	if !ok || !refOK {
+		return nil
+	}
+	if cand.Speed != ref.Speed {
+		return server.OperationFailed(
+			"member %s cannot join port-channel %d: speed %s does not match member %s (%s)",
+			name, id, cand.Speed, reference, ref.Speed)
+	}
+	if cand.Duplex != ref.Duplex {
+		return server.OperationFailed(
+			"member %s cannot join port-channel %d: duplex %s does not match member %s (%s)",
+			name, id, cand.Duplex, reference, ref.Duplex)
+	}
+	if ctx.HasFeature(uidIntfSwitchportGet) {
+		if err := validateLagSwitchport(ctx, id, name, reference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLagSwitchport compares the candidate's switchport mode with
+// the reference member's.
+func validateLagSwitchport(ctx *server.Context, id int, name, reference string) error {
+	ret, err := ctx.Miyagi.Call(ctx, uidIntfSwitchportGet, nil)
+	if err != nil {
+		return err
+	}
+	var ports []miyagiSwitchport
+	if err := json.Unmarshal(ret, &ports); err != nil {
+		return server.OperationFailed("decode %s reply: %v", uidIntfSwitchportGet, err)
+	}
+	modes := make(map[string]string, len(ports))
+	for _, p := range ports {
+		modes[p.Name] = p.Mode
+	}
+	if cand, ref := modes[name], modes[reference]; cand != "" && ref != "" && cand != ref {
+		return server.OperationFailed(
+			"member %s cannot join port-channel %d: switchport mode %s does not match member %s (%s)",
+			name, id, cand, reference, ref)
+	}
+	return nil
+}
+
+// fetchPortTable returns the port.get table keyed by interface name.
+func fetchPortTable(ctx *server.Context) (map[string]miyagiPort, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidPortGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	var ports []miyagiPort
+	if err := json.Unmarshal(ret, &ports); err != nil {
+		return nil, server.OperationFailed("decode %s reply: %v", uidPortGet, err)
+	}
+	out := make(map[string]miyagiPort, len(ports))
+	for _, p := range ports {
+		out[p.Name] = p
+	}
+	return out, nil
+}
+
+// getPortChannels returns every port-channel with its operational
+// status, aggregate speed and per-member state.
+func getPortChannels(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidLagGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var lags []miyagiLag
+	if err := json.Unmarshal(ret, &lags); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidLagGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<port-channels xmlns=%q>`, NSPortChannel)
+	for _, lag := range lags {
+		fmt.Fprintf(&b, "<port-channel><id>%d</id>", lag.ID)
+		fmt.Fprintf(&b, "<oper-status>%s</oper-status><speed>%s</speed>",
+			server.EscapeXML(lag.OperStatus), server.EscapeXML(lag.Speed))
+		if lag.SystemPriority != 0 {
+			fmt.Fprintf(&b, "<system-priority>%d</system-priority>", lag.SystemPriority)
+		}
+		b.WriteString("<members>")
+		for _, m := range lag.Members {
+			fmt.Fprintf(&b, "<member><name>%s</name><status>%s</status>",
+				server.EscapeXML(m.Name), server.EscapeXML(m.Status))
+			if m.Priority != 0 {
+				fmt.Fprintf(&b, "<port-priority>%d</port-priority>", m.Priority)
+			}
+			if m.Timeout != "" {
+				fmt.Fprintf(&b, "<timeout>%s</timeout>", server.EscapeXML(m.Timeout))
+			}
+			b.WriteString("</member>")
+		}
+		b.WriteString("</members></port-channel>")
+	}
+	b.WriteString("</port-channels>")
+	return b.String(), nil
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSMac is the namespace for the MAC address table.
+const NSMac = "yang:mac"
+
+const (
+	uidMacTableGet  = "mac.table.get"
+	uidMacStaticAdd = "mac.static.add"
+	uidMacStaticDel = "mac.static.delete"
+	uidMacAgingSet  = "mac.aging.set"
+	uidMacAgingGet  = "mac.aging.get"
+
+	// FDB aging limits in seconds, matching the device CLI range.
+	macAgingMin = 10
+	macAgingMax = 630
+)
+
+func macHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSMac,
+		Get:       getMacTable,
+		Edit:      editMac,
+		RPC:       macRPC,
+	}
+}
+
+// editMac routes each MAC-table config item by its element name.
+func editMac(ctx *server.Context, item *server.Element) error {
+	switch item.Local() {
+	case "static-entry":
+		return editStaticMac(ctx, item)
+	case "aging":
+		return editMacAging(ctx, item)
+	default:
+		return server.OperationNotSupported("unknown mac item <%s>", item.Local())
+	}
+}
+
+// editMacAging sets the FDB aging time, globally or for one VLAN when
+// a <vlan> child is present.
+func editMacAging(ctx *server.Context, item *server.Element) error {
+	s := item.ChildText("time")
+	if s == "" {
+		return server.MissingElement("time")
+	}
+	secs, err := strconv.Atoi(s)
+	if err != nil || secs < macAgingMin || secs > macAgingMax {
+		return server.InvalidValue("aging time must be %d..%d seconds, got %q", macAgingMin, macAgingMax, s)
+	}
+	arg := map[string]any{"seconds": secs}
+	if v := item.ChildText("vlan"); v != "" {
+		vlan, err := parseVlanID(v)
+		if err != nil {
+			return err
+		}
+		arg["vlan"] = vlan
+	}
+	_, err = ctx.Miyagi.Call(ctx, uidMacAgingSet, arg)
+	return err
+}
+
+// editStaticMac creates or deletes one static FDB entry. The entry
+// needs <address> and <vlan>; a create additionally needs either a
+// <port> or <drop>true</drop> (blackhole entry).
+func editStaticMac(ctx *server.Context, item *server.Element) error {
+	mac, err := NormalizeMAC(item.ChildText("address"))
+	if err != nil {
+		return err
+	}
+	vlan, err := parseVlanID(item.ChildText("vlan"))
+	if err != nil {
+		return err
+	}
+	if item.Attr("operation") == "delete" || item.ChildText("operation") == "delete" {
+		_, err := ctx.Miyagi.Call(ctx, uidMacStaticDel, map[string]any{"mac": mac, "vlan": vlan})
+		return err
+	}
+	arg := map[string]any{"mac": mac, "vlan": vlan}
+	port := item.ChildText("port")
+	drop := item.ChildText("drop") == "true"
+	switch {
+	case port != "" && drop:
+		return server.InvalidValue("static entry %s: port and drop are mutually exclusive", mac)
+	case port != "":
+		arg["ifname"] = port
+	case drop:
+		arg["drop"] = true
+	default:
+		return server.MissingElement("port or drop")
+	}
+	_, err = ctx.Miyagi.Call(ctx, uidMacStaticAdd, arg)
+	return err
+}
+
+// getMacTable returns the full FDB, each entry tagged with its type
+// (dynamic or static).
+func getMacTable(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidMacTableGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var entries []miyagiFdbEntry
+	if err := json.Unmarshal(ret, &entries); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidMacTableGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<mac-table xmlns=%q>`, NSMac)
+	if ctx.HasFeature(uidMacAgingGet) {
+		ret, err := ctx.Miyagi.Call(ctx, uidMacAgingGet, nil)
+		if err != nil {
+			return "", err
+		}
+		var aging struct {
+			Seconds int `json:"seconds"`
+			Vlans   []struct {
+				Vlan    int `json:"vlan"`
+				Seconds int `json:"seconds"`
+			} `json:"vlans"`
+		}
+		if err := json.Unmarshal(ret, &aging); err != nil {
+			return "", server.OperationFailed("decode %s reply: %v", uidMacAgingGet, err)
+		}
+		fmt.Fprintf(&b, "<aging><time>%d</time>", aging.Seconds)
+		for _, v := range aging.Vlans {
+			fmt.Fprintf(&b, "<vlan-override><vlan>%d</vlan><time>%d</time></vlan-override>", v.Vlan, v.Seconds)
+		}
+		b.WriteString("</aging>")
+	}
+	for _, e := range entries {
+		fmt.Fprintf(&b, "<entry><address>%s</address><vlan>%d</vlan><port>%s</port><entry-type>%s</entry-type></entry>",
+			server.EscapeXML(e.Mac), e.Vlan, server.EscapeXML(e.Ifname), server.EscapeXML(e.Type))
+	}
+	b.WriteString("</mac-table>")
+	return b.String(), nil
+}
+
+// miyagiFdbEntry mirrors one entry of the mac.table.get return payload.
+type miyagiFdbEntry struct {
+	Mac    string `json:"mac"`
+	Vlan   int    `json:"vlan"`
+	Ifname string `json:"ifname"`
+	Type   string `json:"type"` // "dynamic" or "static"
+}
+
+// macRPC serves <find-mac>, which looks an address up in the FDB and
+// returns every VLAN/port where it is learned.
+func macRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "find-mac" {
+		return "", server.OperationNotSupported("unknown mac rpc <%s>", op.Local())
+	}
+	want, err := NormalizeMAC(op.ChildText("address"))
+	if err != nil {
+		return "", err
+	}
+	ret, err := ctx.Miyagi.Call(ctx, uidMacTableGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var entries []miyagiFdbEntry
+	if err := json.Unmarshal(ret, &entries); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidMacTableGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<entries xmlns=%q>`, NSMac)
+	for _, e := range entries {
+		got, err := NormalizeMAC(e.Mac)
+		if err != nil || got != want {
+			continue
+		}
+		fmt.Fprintf(&b, "<entry><address>%s</address><vlan>%d</vlan><port>%s</port><type>%s</type></entry>",
+			server.EscapeXML(got), e.Vlan, server.EscapeXML(e.Ifname), server.EscapeXML(e.Type))
+	}
+	b.WriteString("</entries>")
+	return b.String(), nil
+}
+
+// NormalizeMAC canonicalizes a MAC address to lower-case colon form,
+// accepting colon, dash and Cisco dotted notations.
+func NormalizeMAC(s string) (string, error) {
+	hex := strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.TrimSpace(s)))
+	if len(hex) != 12 {
+		return "", server.InvalidValue("%q is not a MAC address", s)
+	}
+	for _, r := range hex {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return "", server.InvalidValue("%q is not a MAC address", s)
+		}
+	}
+	var parts []string
+	for i := 0; i < 12; i += 2 {
+		parts = append(parts, hex[i:i+2])
+	}
+	return strings.Join(parts, ":"), nil
+}
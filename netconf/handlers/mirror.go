@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSMirror is the namespace for port mirroring (SPAN) state.
+const NSMirror = "yang:mirror"
+
+const uidMirrorSessionsGet = "mirror.sessions.get"
+
+func mirrorHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSMirror,
+		Get:       getMirrorSessions,
+	}
+}
+
+// miyagiMirrorSession mirrors one entry of the mirror.sessions.get
+// return payload.
+type miyagiMirrorSession struct {
+	ID      int    `json:"id"`
+	Active  bool   `json:"active"`
+	Reason  string `json:"reason"` // why an inactive session is down
+	Sources []struct {
+		Ifname    string `json:"ifname"`
+		Direction string `json:"direction"` // rx, tx or both
+	} `json:"sources"`
+	Destination string `json:"destination"`
+	Drops       uint64 `json:"drops"`
+}
+
+// getMirrorSessions reports each mirror session's operational state:
+// whether it is forwarding, how its sources and destination resolved,
+// and the drop counter on the destination port.
+func getMirrorSessions(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidMirrorSessionsGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var sessions []miyagiMirrorSession
+	if err := json.Unmarshal(ret, &sessions); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidMirrorSessionsGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<mirror-sessions xmlns=%q>`, NSMirror)
+	for _, s := range sessions {
+		b.WriteString("<session>")
+		fmt.Fprintf(&b, "<id>%d</id><active>%t</active>", s.ID, s.Active)
+		if !s.Active && s.Reason != "" {
+			fmt.Fprintf(&b, "<reason>%s</reason>", server.EscapeXML(s.Reason))
+		}
+		for _, src := range s.Sources {
+			fmt.Fprintf(&b, "<source><port>%s</port><direction>%s</direction></source>",
+				server.EscapeXML(src.Ifname), server.EscapeXML(src.Direction))
+		}
+		fmt.Fprintf(&b, "<destination>%s</destination><drops>%d</drops>",
+			server.EscapeXML(s.Destination), s.Drops)
+		b.WriteString("</session>")
+	}
+	b.WriteString("</mirror-sessions>")
+	return b.String(), nil
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSOob is the namespace for the out-of-band management port. The oob
+// port is not part of the switching fabric and uses its own Miyagi UIDs
+// rather than the front-panel port API.
+const NSOob = "yang:oob"
+
+const (
+	uidOobAddrSet    = "oob.address.set"
+	uidOobGatewaySet = "oob.gateway.set"
+	uidOobDhcpSet    = "oob.dhcp.set"
+	uidOobSpeedSet   = "oob.speed.set"
+	uidOobGet        = "oob.get"
+)
+
+// OobData is the parsed form of one <oob> config item. Pointer fields
+// distinguish "not present" from an explicit value.
+type OobData struct {
+	IP      string
+	Mask    string
+	Gateway string
+	DHCP    *bool
+	Speed   string // e.g. "100", "1000", "auto"
+	Duplex  string // "full", "half" or "" when speed is auto
+}
+
+// ParseOob extracts OobData from an <oob> item.
+func ParseOob(item *server.Element) (*OobData, error) {
+	data := &OobData{
+		IP:      item.ChildText("ip"),
+		Mask:    item.ChildText("mask"),
+		Gateway: item.ChildText("gateway"),
+		Speed:   item.ChildText("speed"),
+		Duplex:  item.ChildText("duplex"),
+	}
+	if c := item.Child("dhcp"); c != nil {
+		v := strings.TrimSpace(c.Text) == "true"
+		data.DHCP = &v
+	}
+	if (data.IP == "") != (data.Mask == "") {
+		return nil, server.InvalidValue("oob address needs both <ip> and <mask>")
+	}
+	if data.IP != "" {
+		if err := checkAddress("/oob/ip", data.IP); err != nil {
+			return nil, err
+		}
+		if err := checkMask("/oob/mask", data.Mask); err != nil {
+			return nil, err
+		}
+	}
+	if data.Gateway != "" {
+		if err := checkAddress("/oob/gateway", data.Gateway); err != nil {
+			return nil, err
+		}
+	}
+	if data.DHCP != nil && *data.DHCP && data.IP != "" {
+		return nil, server.InvalidValue("oob cannot combine a static address with dhcp")
+	}
+	if data.Duplex != "" && data.Duplex != "full" && data.Duplex != "half" {
+		return nil, server.InvalidValue("oob duplex must be full or half, got %q", data.Duplex)
+	}
+	return data, nil
+}
+
+func oobHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSOob,
+		Get:       getOob,
+		Edit:      editOob,
+	}
+}
+
+// editOob applies the oob settings that are present in the item; each
+// maps to its own Miyagi call.
+func editOob(ctx *server.Context, item *server.Element) error {
+	data, err := ParseOob(item)
+	if err != nil {
+		return err
+	}
+	if data.DHCP != nil {
+		if _, err := ctx.Miyagi.Call(ctx, uidOobDhcpSet, map[string]any{"enable": *data.DHCP}); err != nil {
+			return err
+		}
+	}
+	if data.IP != "" {
+		if _, err := ctx.Miyagi.Call(ctx, uidOobAddrSet, map[string]any{"ip": data.IP, "mask": data.Mask}); err != nil {
+			return err
+		}
+	}
+	if data.Gateway != "" {
+		if _, err := ctx.Miyagi.Call(ctx, uidOobGatewaySet, map[string]any{"gateway": data.Gateway}); err != nil {
+			return err
+		}
+	}
+	if data.Speed != "" {
+		arg := map[string]any{"speed": data.Speed}
+		if data.Duplex != "" {
+			arg["duplex"] = data.Duplex
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidOobSpeedSet, arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// miyagiOob mirrors the oob.get return payload.
+type miyagiOob struct {
+	IP      string `json:"ip"`
+	Mask    string `json:"mask"`
+	Gateway string `json:"gateway"`
+	DHCP    bool   `json:"dhcp"`
+	Speed   string `json:"speed"`
+	Duplex  string `json:"duplex"`
+}
+
+func getOob(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidOobGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var oob miyagiOob
+	if err := json.Unmarshal(ret, &oob); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidOobGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<oob xmlns=%q>`, NSOob)
+	fmt.Fprintf(&b, "<ip>%s</ip><mask>%s</mask><gateway>%s</gateway>",
+		server.EscapeXML(oob.IP), server.EscapeXML(oob.Mask), server.EscapeXML(oob.Gateway))
+	fmt.Fprintf(&b, "<dhcp>%t</dhcp><speed>%s</speed><duplex>%s</duplex>",
+		oob.DHCP, server.EscapeXML(oob.Speed), server.EscapeXML(oob.Duplex))
+	b.WriteString("</oob>")
+	return b.String(), nil
+}
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSPort is the namespace for physical port configuration.
+const NSPort = "yang:port"
+
+const (
+	uidPortSpeedSet   = "port.speed.set"
+	uidPortDuplexSet  = "port.duplex.set"
+	uidPortAutoNegSet = "port.autoneg.set"
+	uidPortFlowCtlSet = "port.flowcontrol.set"
+	uidPortAdminSet   = "port.admin.set"
+	uidPortIngressSet = "port.rate_limit.ingress.set"
+	uidPortEgressSet  = "port.rate_limit.egress.set"
+	uidPortGet        = "port.get"
+
+	// maxRateKbps caps rate-limit values at 100 Gbit/s, past any
+	// port speed this hardware family offers.
+	maxRateKbps = 100_000_000
+)
+
+// PortData is the parsed form of one <port> config item. Empty strings
+// and nil pointers mean "leave unchanged".
+type PortData struct {
+	Name        string
+	Shutdown    *bool
+	Speed       string
+	Duplex      string // "full" or "half"
+	AutoNeg     *bool
+	FlowControl string // "on", "off" or "auto"
+
+	// Ingress policing and egress shaping; nil means unchanged. A
+	// rate of 0 removes the limit.
+	Ingress *RateLimit
+	Egress  *RateLimit
+}
+
+// RateLimit is one direction's policing/shaping configuration.
+type RateLimit struct {
+	RateKbps int
+	BurstKB  int // 0 = device default burst
+}
+
+func portHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSPort,
+		Get:       getPorts,
+		Edit:      editPort,
+		RPC:       portRPC,
+	}
+}
+
+// portRPC serves <port-bounce>, which shuts a port down, waits the
+// requested delay and brings it back up in one atomic action, so
+// clients do not have to sequence two edit-configs with sleeps.
+func portRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "port-bounce" {
+		return "", server.OperationNotSupported("unknown port rpc <%s>", op.Local())
+	}
+	name, err := InterfaceRef(ctx, op)
+	if err != nil {
+		return "", err
+	}
+	if _, _, err := ParseUnitPort(name); err != nil {
+		return "", err
+	}
+	delay := 2 * time.Second
+	if txt := op.ChildText("delay"); txt != "" {
+		d, err := time.ParseDuration(txt)
+		if err != nil {
+			return "", server.InvalidValue("delay %q: %v", txt, err)
+		}
+		if d < 0 || d > time.Minute {
+			return "", server.InvalidValue("delay must be between 0s and 1m, got %s", d)
+		}
+		delay = d
+	}
+	if _, err := ctx.Miyagi.Call(ctx, uidPortAdminSet, map[string]any{"ifname": name, "enable": false}); err != nil {
+		return "", err
+	}
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		// The client went away mid-bounce; still bring the port back
+		// up rather than leaving it down.
+	}
+	if _, err := ctx.Miyagi.Call(ctx.Server.BackgroundContext(), uidPortAdminSet, map[string]any{"ifname": name, "enable": true}); err != nil {
+		return "", server.OperationFailed("port %s was disabled but re-enable failed: %v", name, err)
+	}
+	return "<ok/>", nil
+}
+
+// ParsePort extracts PortData from a <port> item.
+func ParsePort(item *server.Element) (*PortData, error) {
+	data := &PortData{
+		Name:        item.ChildText("name"),
+		Speed:       item.ChildText("speed"),
+		Duplex:      item.ChildText("duplex"),
+		FlowControl: item.ChildText("flow-control"),
+	}
+	if c := item.Child("shutdown"); c != nil {
+		v := strings.TrimSpace(c.Text) == "true"
+		data.Shutdown = &v
+	}
+	if c := item.Child("auto-negotiation"); c != nil {
+		v := strings.TrimSpace(c.Text) == "true"
+		data.AutoNeg = &v
+	}
+	if data.Duplex != "" && data.Duplex != "full" && data.Duplex != "half" {
+		return nil, server.InvalidValue("port %s: duplex must be full or half, got %q", data.Name, data.Duplex)
+	}
+	switch data.FlowControl {
+	case "", "on", "off", "auto":
+	default:
+		return nil, server.InvalidValue("port %s: flow-control must be on, off or auto, got %q", data.Name, data.FlowControl)
+	}
+	if data.AutoNeg != nil && *data.AutoNeg && (data.Speed != "" || data.Duplex != "") {
+		return nil, server.InvalidValue("port %s: speed/duplex cannot be set together with auto-negotiation", data.Name)
+	}
+	if rl := item.Child("rate-limit"); rl != nil {
+		var err error
+		if data.Ingress, err = parseRateLimit(data.Name, rl.Child("ingress")); err != nil {
+			return nil, err
+		}
+		if data.Egress, err = parseRateLimit(data.Name, rl.Child("egress")); err != nil {
+			return nil, err
+		}
+		if data.Ingress == nil && data.Egress == nil {
+			return nil, server.MissingElement("ingress or egress")
+		}
+	}
+	return data, nil
+}
+
+// parseRateLimit reads one direction of a <rate-limit> block; dir may
+// be nil when that direction is absent.
+func parseRateLimit(port string, dir *server.Element) (*RateLimit, error) {
+	if dir == nil {
+		return nil, nil
+	}
+	rate := dir.ChildText("rate")
+	if rate == "" {
+		return nil, server.InvalidValue("port %s: rate-limit needs a <rate> in kbps", port)
+	}
+	rl := &RateLimit{}
+	v, err := strconv.Atoi(rate)
+	if err != nil || v < 0 || v > maxRateKbps {
+		return nil, server.InvalidValue("port %s: rate must be 0..%d kbps, got %q", port, maxRateKbps, rate)
+	}
+	rl.RateKbps = v
+	if burst := dir.ChildText("burst"); burst != "" {
+		v, err := strconv.Atoi(burst)
+		if err != nil || v < 1 || v > 16384 {
+			return nil, server.InvalidValue("port %s: burst must be 1..16384 KB, got %q", port, burst)
+		}
+		rl.BurstKB = v
+	}
+	return rl, nil
+}
+
+// editPort applies the port settings that are present in the item.
+func editPort(ctx *server.Context, item *server.Element) error {
+	data, err := ParsePort(item)
+	if err != nil {
+		return err
+	}
+	if data.Name, err = InterfaceRef(ctx, item); err != nil {
+		return err
+	}
+	// Validate unit-aware names ("2/ge5") up front on stacks.
+	if _, _, err := ParseUnitPort(data.Name); err != nil {
+		return err
+	}
+	ifArg := func(extra map[string]any) map[string]any {
+		arg := map[string]any{"ifname": data.Name}
+		for k, v := range extra {
+			arg[k] = v
+		}
+		return arg
+	}
+	if data.Shutdown != nil {
+		if _, err := ctx.Miyagi.Call(ctx, uidPortAdminSet, ifArg(map[string]any{"enable": !*data.Shutdown})); err != nil {
+			return err
+		}
+	}
+	if data.AutoNeg != nil {
+		if _, err := ctx.Miyagi.Call(ctx, uidPortAutoNegSet, ifArg(map[string]any{"enable": *data.AutoNeg})); err != nil {
+			return err
+		}
+	}
+	if data.Speed != "" {
+		if _, err := ctx.Miyagi.Call(ctx, uidPortSpeedSet, ifArg(map[string]any{"speed": data.Speed})); err != nil {
+			return err
+		}
+	}
+	if data.Duplex != "" {
+		if _, err := ctx.Miyagi.Call(ctx, uidPortDuplexSet, ifArg(map[string]any{"duplex": data.Duplex})); err != nil {
+			return err
+		}
+	}
+	if data.FlowControl != "" {
+		if _, err := ctx.Miyagi.Call(ctx, uidPortFlowCtlSet, ifArg(map[string]any{"mode": data.FlowControl})); err != nil {
+			return err
+		}
+	}
+	for uid, rl := range map[string]*RateLimit{
+		uidPortIngressSet: data.Ingress,
+		uidPortEgressSet:  data.Egress,
+	} {
+		if rl == nil {
+			continue
+		}
+		arg := map[string]any{"rate_kbps": rl.RateKbps}
+		if rl.BurstKB != 0 {
+			arg["burst_kb"] = rl.BurstKB
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uid, ifArg(arg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// miyagiPort mirrors one entry of the port.get return payload.
+type miyagiPort struct {
+	Name        string `json:"ifname"`
+	AdminStatus string `json:"admin_status"`
+	OperStatus  string `json:"oper_status"`
+	Speed       string `json:"speed"`
+	Duplex      string `json:"duplex"` // "full" or "half"
+	AutoNeg     *bool  `json:"autoneg"`
+	FlowControl string `json:"flow_control"` // "on", "off" or "auto"
+	Description string `json:"description"`
+	IngressKbps int    `json:"ingress_rate_kbps"`
+	EgressKbps  int    `json:"egress_rate_kbps"`
+}
+
+// getPorts returns status, speed, duplex, negotiation settings and
+// description for the selected ports. An empty filter or an "all"
+// selector lists every port in a single device call; a filter naming
+// individual ports or dash ranges ("ge1-8") fans out one query per
+// port, concurrently since the queries are independent.
+func getPorts(ctx *server.Context, filter *server.Element) (string, error) {
+	names, err := portFilterNames(filter)
+	if err != nil {
+		return "", err
+	}
+	var ports []miyagiPort
+	if len(names) == 0 {
+		ret, err := ctx.Miyagi.Call(ctx, uidPortGet, nil)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(ret, &ports); err != nil {
+			return "", server.OperationFailed("decode %s reply: %v", uidPortGet, err)
+		}
+	} else if ports, err = fetchPorts(ctx, names); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<ports xmlns=%q>`, NSPort)
+	for _, p := range ports {
+		b.WriteString("<port>")
+		fmt.Fprintf(&b, "<name>%s</name>", server.EscapeXML(p.Name))
+		fmt.Fprintf(&b, "<admin-status>%s</admin-status><oper-status>%s</oper-status>",
+			server.EscapeXML(p.AdminStatus), server.EscapeXML(p.OperStatus))
+		fmt.Fprintf(&b, "<speed>%s</speed>", server.EscapeXML(p.Speed))
+		if p.Duplex != "" {
+			fmt.Fprintf(&b, "<duplex>%s</duplex>", server.EscapeXML(p.Duplex))
+		}
+		if p.AutoNeg != nil {
+			fmt.Fprintf(&b, "<auto-negotiation>%t</auto-negotiation>", *p.AutoNeg)
+		}
+		if p.FlowControl != "" {
+			fmt.Fprintf(&b, "<flow-control>%s</flow-control>", server.EscapeXML(p.FlowControl))
+		}
+		fmt.Fprintf(&b, "<description>%s</description>", server.EscapeXML(p.Description))
+		if p.IngressKbps != 0 || p.EgressKbps != 0 {
+			b.WriteString("<rate-limit>")
+			if p.IngressKbps != 0 {
+				fmt.Fprintf(&b, "<ingress><rate>%d</rate></ingress>", p.IngressKbps)
+			}
+			if p.EgressKbps != 0 {
+				fmt.Fprintf(&b, "<egress><rate>%d</rate></egress>", p.EgressKbps)
+			}
+			b.WriteString("</rate-limit>")
+		}
+		b.WriteString("</port>")
+	}
+	b.WriteString("</ports>")
+	return b.String(), nil
+}
+
+// portFetchConcurrency bounds the parallel per-port queries of a
+// filtered get, so a wide range cannot flood the daemon.
+const portFetchConcurrency = 8
+
+// portFilterNames extracts the port selection from a <ports> filter:
+// each <port><name> child names one port or a dash range. A nil
+// filter, a <port> without a <name> or the literal "all" select every
+// port, reported as an empty list.
+func portFilterNames(filter *server.Element) ([]string, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	var names []string
+	all := false
+	var nameErr error
+	filter.EachChild("port", func(p *server.Element) {
+		name := p.ChildText("name")
+		if name == "" || name == "all" {
+			all = true
+			return
+		}
+		expanded, err := expandPortRange(name)
+		if err != nil {
+			nameErr = err
+			return
+		}
+		names = append(names, expanded...)
+	})
+	if nameErr != nil {
+		return nil, nameErr
+	}
+	if all {
+		return nil, nil
+	}
+	return names, nil
+}
+
+// maxPortRange bounds one dash range; anything wider than the largest
+// stack this hardware family builds is a typo.
+const maxPortRange = 256
+
+// expandPortRange expands a dash range like "ge1-8" or "ge1-ge8" into
+// individual port names; a name without a dash passes through.
+func expandPortRange(name string) ([]string, error) {
+	left, right, ok := strings.Cut(name, "-")
+	if !ok {
+		return []string{name}, nil
+	}
+	prefix, start, err := splitPortName(left)
+	if err != nil {
+		return nil, server.InvalidValue("bad port range %q", name)
+	}
+	right = strings.TrimPrefix(right, prefix)
+	end, err := strconv.Atoi(right)
+	if err != nil || end < start || end-start+1 > maxPortRange {
+		return nil, server.InvalidValue("bad port range %q", name)
+	}
+	out := make([]string, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		out = append(out, fmt.Sprintf("%s%d", prefix, n))
+	}
+	return out, nil
+}
+
+// splitPortName splits a port name into its type prefix and trailing
+// number, e.g. "ge12" -> "ge", 12.
+func splitPortName(s string) (string, int, error) {
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+	if i == len(s) {
+		return "", 0, fmt.Errorf("port name %q has no trailing number", s)
+	}
+	num, err := strconv.Atoi(s[i:])
+	return s[:i], num, err
+}
+
+// fetchPorts queries the named ports concurrently and concatenates the
+// results in the requested order.
+func fetchPorts(ctx *server.Context, names []string) ([]miyagiPort, error) {
+	sem := make(chan struct{}, portFetchConcurrency)
+	results := make([][]miyagiPort, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			ret, err := ctx.Miyagi.Call(ctx, uidPortGet, map[string]any{"ifname": name})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if err := json.Unmarshal(ret, &results[i]); err != nil {
+				errs[i] = server.OperationFailed("decode %s reply: %v", uidPortGet, err)
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	var out []miyagiPort
+	for _, r := range results {
+		out = append(out, r...)
+	}
+	return out, nil
+}
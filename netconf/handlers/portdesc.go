@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSPortDescription is the namespace for per-port descriptions.
+const NSPortDescription = "yang:port_description"
+
+const uidPortDescriptionSet = "port.description.set"
+
+// portDescWorkers bounds the concurrent entries of one bulk
+// <set-descriptions>, so a provisioning run with thousands of entries
+// cannot pile goroutines onto the Miyagi connection.
+const portDescWorkers = 8
+
+func portDescriptionHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSPortDescription,
+		Edit:      editPortDescription,
+		RPC:       portDescriptionRPC,
+	}
+}
+
+// portDescriptionRPC serves <set-descriptions>, a bulk form of the
+// description edit for provisioning runs (e.g. generated from a
+// patch-panel sheet). Entries are applied concurrently and the reply
+// carries one result per interface instead of failing the whole batch
+// on the first error.
+func portDescriptionRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "set-descriptions" {
+		return "", server.OperationNotSupported("unknown port_description rpc <%s>", op.Local())
+	}
+	var entries []*server.Element
+	op.EachChild("entry", func(e *server.Element) { entries = append(entries, e) })
+	if len(entries) == 0 {
+		return "", server.MissingElement("entry")
+	}
+	type result struct {
+		name string
+		err  error
+	}
+	results := make([]result, len(entries))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < portDescWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				e := entries[i]
+				name, err := InterfaceRef(ctx, e)
+				if err != nil {
+					results[i] = result{name: e.ChildText("name"), err: err}
+					continue
+				}
+				_, err = ctx.Miyagi.Call(ctx, uidPortDescriptionSet, map[string]any{
+					"ifname":      name,
+					"description": e.ChildText("description"),
+				})
+				results[i] = result{name: name, err: err}
+			}
+		}()
+	}
+	for i := range entries {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<results xmlns=%q>`, NSPortDescription)
+	for _, r := range results {
+		fmt.Fprintf(&b, "<result><name>%s</name>", server.EscapeXML(r.name))
+		if r.err != nil {
+			fmt.Fprintf(&b, "<status>error</status><error>%s</error>", server.EscapeXML(r.err.Error()))
+		} else {
+			b.WriteString("<status>ok</status>")
+		}
+		b.WriteString("</result>")
+	}
+	b.WriteString("</results>")
+	return b.String(), nil
+}
+
+// editPortDescription sets or clears one port's description. A delete
+// operation or an empty <description> clears it.
+func editPortDescription(ctx *server.Context, item *server.Element) error {
+	name, err := InterfaceRef(ctx, item)
+	if err != nil {
+		return err
+	}
+	desc := item.ChildText("description")
+	if item.Attr("operation") == "delete" {
+		desc = ""
+	}
+	_, err = ctx.Miyagi.Call(ctx, uidPortDescriptionSet, map[string]any{
+		"ifname":      name,
+		"description": desc,
+	})
+	return err
+}
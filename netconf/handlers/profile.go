@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// modelProfiles maps a model-number prefix to the namespaces that
+// hardware family cannot deliver. Models without an entry get the full
+// namespace set.
+var modelProfiles = map[string][]string{
+	// Entry-level standalone switches: no stacking, no port-channels.
+	"S110": {NSStack, NSPortChannel},
+	// Lite SKUs: no stacking and no SNMP agent.
+	"S210": {NSStack, NSSnmp},
+}
+
+// ProfileForModel builds the feature profile for a device model, as
+// reported by Miyagi system info at startup. Unknown and empty models
+// yield a nil profile, which enables every namespace.
+func ProfileForModel(model string) *server.Profile {
+	for prefix, disabled := range modelProfiles {
+		if !strings.HasPrefix(model, prefix) {
+			continue
+		}
+		p := &server.Profile{Model: model, Disabled: make(map[string]bool, len(disabled))}
+		for _, ns := range disabled {
+			p.Disabled[ns] = true
+		}
+		return p
+	}
+	return nil
+}
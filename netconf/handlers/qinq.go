@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSSwitchport is the namespace for per-port switchport extensions;
+// today that is 802.1ad VLAN stacking (QinQ).
+const NSSwitchport = "yang:switchport"
+
+const (
+	uidSwitchportModeSet    = "interface.switchport.mode.set"
+	uidSwitchportAccessSet  = "interface.switchport.access_vlan.set"
+	uidSwitchportNativeSet  = "interface.switchport.native_vlan.set"
+	uidSwitchportAllowedSet = "interface.switchport.allowed_vlans.set"
+
+	uidSwitchportTunnelSet = "interface.switchport.tunnel.set"
+	uidSwitchportTpidSet   = "interface.switchport.tpid.set"
+	uidSwitchportSvlanSet  = "interface.switchport.svlan.set"
+	uidVlanTranslationAdd  = "interface.vlan_translation.add"
+	uidVlanTranslationDel  = "interface.vlan_translation.delete"
+	uidVlanTranslationGet  = "interface.vlan_translation.get"
+)
+
+func switchportHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSSwitchport,
+		Get:       getSwitchports,
+		Edit:      editSwitchport,
+	}
+}
+
+// editSwitchport applies switchport settings to one port: <mode> plus
+// the VLAN membership leaves (<access-vlan>, <native-vlan>,
+// <allowed-vlans>), and the QinQ settings — <tunnel-mode> enables or
+// disables dot1q tunnelling, <tpid> selects the outer ethertype, and
+// <s-vlan> assigns the service VLAN pushed on ingress. Referenced
+// VLANs are checked against the device VLAN table first (and created
+// when the server runs with auto-create).
+func editSwitchport(ctx *server.Context, item *server.Element) error {
+	if item.Local() == "vlan-translation" {
+		return editVlanTranslation(ctx, item)
+	}
+	name, err := InterfaceRef(ctx, item)
+	if err != nil {
+		return err
+	}
+	applied := false
+	if mode := item.ChildText("mode"); mode != "" {
+		switch mode {
+		case "access", "trunk", "tunnel":
+		default:
+			return server.InvalidValue("port %s: mode must be access, trunk or tunnel, got %q", name, mode)
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidSwitchportModeSet, map[string]any{"ifname": name, "mode": mode}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	for tag, uid := range map[string]string{
+		"access-vlan": uidSwitchportAccessSet,
+		"native-vlan": uidSwitchportNativeSet,
+	} {
+		s := item.ChildText(tag)
+		if s == "" {
+			continue
+		}
+		id, err := parseVlanID(s)
+		if err != nil {
+			return err
+		}
+		if err := ensureVlansExist(ctx, []int{id}); err != nil {
+			return err
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uid, map[string]any{"ifname": name, "vlan": id}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if s := item.ChildText("allowed-vlans"); s != "" {
+		ids, err := parseVlanList(s)
+		if err != nil {
+			return err
+		}
+		if err := ensureVlansExist(ctx, ids); err != nil {
+			return err
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidSwitchportAllowedSet, map[string]any{"ifname": name, "vlans": ids}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if mode := item.ChildText("tunnel-mode"); mode != "" {
+		if mode != "enabled" && mode != "disabled" {
+			return server.InvalidValue("port %s: tunnel-mode must be enabled or disabled, got %q", name, mode)
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidSwitchportTunnelSet, map[string]any{"ifname": name, "enable": mode == "enabled"}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if tpid := item.ChildText("tpid"); tpid != "" {
+		switch tpid {
+		case "0x8100", "0x88a8", "0x9100":
+		default:
+			return server.InvalidValue("port %s: tpid must be 0x8100, 0x88a8 or 0x9100, got %q", name, tpid)
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidSwitchportTpidSet, map[string]any{"ifname": name, "tpid": tpid}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if s := item.ChildText("s-vlan"); s != "" {
+		svlan, err := parseVlanID(s)
+		if err != nil {
+			return err
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidSwitchportSvlanSet, map[string]any{"ifname": name, "vlan": svlan}); err != nil {
+			return err
+		}
+		applied = true
+	}
+	if !applied {
+		return server.MissingElement("mode, access-vlan, native-vlan, allowed-vlans, tunnel-mode, tpid or s-vlan")
+	}
+	return nil
+}
+
+// editVlanTranslation creates or deletes one VLAN mapping rule on a
+// port: frames arriving (ingress) or leaving (egress) on <from> are
+// rewritten to <to>.
+func editVlanTranslation(ctx *server.Context, item *server.Element) error {
+	name, err := InterfaceRef(ctx, item)
+	if err != nil {
+		return err
+	}
+	dir := item.ChildText("direction")
+	if dir != "ingress" && dir != "egress" {
+		return server.InvalidValue("port %s: direction must be ingress or egress, got %q", name, dir)
+	}
+	from, err := parseVlanID(item.ChildText("from"))
+	if err != nil {
+		return err
+	}
+	to, err := parseVlanID(item.ChildText("to"))
+	if err != nil {
+		return err
+	}
+	uid := uidVlanTranslationAdd
+	if item.Attr("operation") == "delete" || item.ChildText("operation") == "delete" {
+		uid = uidVlanTranslationDel
+	}
+	_, err = ctx.Miyagi.Call(ctx, uid, map[string]any{"ifname": name, "direction": dir, "from": from, "to": to})
+	return err
+}
+
+// getSwitchports returns the per-port switchport mode including the
+// QinQ attributes for tunnelling ports.
+func getSwitchports(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidIntfSwitchportGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var ports []miyagiSwitchport
+	if err := json.Unmarshal(ret, &ports); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidIntfSwitchportGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<switchports xmlns=%q>`, NSSwitchport)
+	for _, p := range ports {
+		b.WriteString("<switchport>")
+		fmt.Fprintf(&b, "<name>%s</name><mode>%s</mode>", server.EscapeXML(p.Name), server.EscapeXML(p.Mode))
+		if p.Mode == "tunnel" {
+			if p.TPID != "" {
+				fmt.Fprintf(&b, "<tpid>%s</tpid>", server.EscapeXML(p.TPID))
+			}
+			if p.SVlan != 0 {
+				fmt.Fprintf(&b, "<s-vlan>%d</s-vlan>", p.SVlan)
+			}
+		}
+		b.WriteString("</switchport>")
+	}
+	b.WriteString("</switchports>")
+	if ctx.HasFeature(uidVlanTranslationGet) {
+		trans, err := buildVlanTranslations(ctx)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(trans)
+	}
+	return b.String(), nil
+}
+
+// buildVlanTranslations renders the active VLAN mapping rules as a
+// sibling element of the switchport list.
+func buildVlanTranslations(ctx *server.Context) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidVlanTranslationGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var rules []struct {
+		Ifname    string `json:"ifname"`
+		Direction string `json:"direction"`
+		From      int    `json:"from"`
+		To        int    `json:"to"`
+	}
+	if err := json.Unmarshal(ret, &rules); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidVlanTranslationGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<vlan-translations xmlns=%q>`, NSSwitchport)
+	for _, r := range rules {
+		fmt.Fprintf(&b, "<vlan-translation><name>%s</name><direction>%s</direction><from>%d</from><to>%d</to></vlan-translation>",
+			server.EscapeXML(r.Ifname), server.EscapeXML(r.Direction), r.From, r.To)
+	}
+	b.WriteString("</vlan-translations>")
+	return b.String(), nil
+}
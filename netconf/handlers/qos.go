@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSQos is the namespace for quality-of-service configuration.
+const NSQos = "yang:qos"
+
+const (
+	uidQosCosQueueSet  = "qos.cos_queue.set"
+	uidQosCosQueueGet  = "qos.cos_queue.get"
+	uidQosTrustSet     = "qos.trust.set"
+	uidQosDscpQueueSet = "qos.dscp_queue.set"
+	uidQosDefPrioSet   = "qos.default_priority.set"
+	uidQosDefPrioGet   = "qos.default_priority.get"
+
+	// qosQueues is how many egress queues the hardware exposes.
+	qosQueues = 8
+)
+
+func qosHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSQos,
+		Get:       getQos,
+		Edit:      editQos,
+	}
+}
+
+// editQos routes each QoS config item by its element name.
+func editQos(ctx *server.Context, item *server.Element) error {
+	switch item.Local() {
+	case "cos-queue-map":
+		return editCosQueueMap(ctx, item)
+	case "trust":
+		return editQosTrust(ctx, item)
+	case "dscp-queue-map":
+		return editDscpQueueMap(ctx, item)
+	case "default-priority":
+		return editDefaultPriority(ctx, item)
+	default:
+		return server.OperationNotSupported("unknown qos item <%s>", item.Local())
+	}
+}
+
+// editQosTrust sets a port's trust mode: which packet marking, if any,
+// the ingress classifier believes.
+func editQosTrust(ctx *server.Context, item *server.Element) error {
+	name, err := InterfaceRef(ctx, item)
+	if err != nil {
+		return err
+	}
+	mode := item.ChildText("mode")
+	switch mode {
+	case "cos", "dscp", "untrusted":
+	case "":
+		return server.MissingElement("mode")
+	default:
+		return server.InvalidValue("port %s: trust mode must be cos, dscp or untrusted, got %q", name, mode)
+	}
+	_, err = ctx.Miyagi.Call(ctx, uidQosTrustSet, map[string]any{"ifname": name, "mode": mode})
+	return err
+}
+
+// editDscpQueueMap applies DSCP to egress queue mappings. <dscp>
+// accepts a single code point or an inclusive range like "16-23".
+func editDscpQueueMap(ctx *server.Context, item *server.Element) error {
+	var mapErr error
+	applied := false
+	item.EachChild("map", func(m *server.Element) {
+		if mapErr != nil {
+			return
+		}
+		lo, hi, err := parseDscpRange(m.ChildText("dscp"))
+		if err != nil {
+			mapErr = err
+			return
+		}
+		queue, err := parseQosValue(m, "queue", qosQueues)
+		if err != nil {
+			mapErr = err
+			return
+		}
+		for dscp := lo; dscp <= hi; dscp++ {
+			if _, err := ctx.Miyagi.Call(ctx, uidQosDscpQueueSet, map[string]any{"dscp": dscp, "queue": queue}); err != nil {
+				mapErr = err
+				return
+			}
+		}
+		applied = true
+	})
+	if mapErr != nil {
+		return mapErr
+	}
+	if !applied {
+		return server.MissingElement("map")
+	}
+	return nil
+}
+
+// editDefaultPriority sets the 802.1p priority a port assigns to
+// untagged ingress traffic.
+func editDefaultPriority(ctx *server.Context, item *server.Element) error {
+	name, err := InterfaceRef(ctx, item)
+	if err != nil {
+		return err
+	}
+	prio, err := parseQosValue(item, "priority", 7)
+	if err != nil {
+		return err
+	}
+	_, err = ctx.Miyagi.Call(ctx, uidQosDefPrioSet, map[string]any{"ifname": name, "priority": prio})
+	return err
+}
+
+// parseDscpRange parses "46" or "16-23" into an inclusive [lo, hi]
+// pair within the 6-bit DSCP space.
+func parseDscpRange(s string) (lo, hi int, err error) {
+	if s == "" {
+		return 0, 0, server.MissingElement("dscp")
+	}
+	loStr, hiStr, isRange := strings.Cut(s, "-")
+	if !isRange {
+		hiStr = loStr
+	}
+	lo, err1 := strconv.Atoi(strings.TrimSpace(loStr))
+	hi, err2 := strconv.Atoi(strings.TrimSpace(hiStr))
+	if err1 != nil || err2 != nil || lo < 0 || hi > 63 || lo > hi {
+		return 0, 0, server.InvalidValue("<dscp> must be a value or range within 0..63, got %q", s)
+	}
+	return lo, hi, nil
+}
+
+// editCosQueueMap applies 802.1p CoS to egress queue mappings, either
+// globally or for one port when the item carries interface addressing.
+func editCosQueueMap(ctx *server.Context, item *server.Element) error {
+	arg := map[string]any{}
+	if item.Child("name") != nil || item.Child("ifindex") != nil {
+		name, err := InterfaceRef(ctx, item)
+		if err != nil {
+			return err
+		}
+		arg["ifname"] = name
+	}
+	var mapErr error
+	applied := false
+	item.EachChild("map", func(m *server.Element) {
+		if mapErr != nil {
+			return
+		}
+		cos, err := parseQosValue(m, "cos", 7)
+		if err != nil {
+			mapErr = err
+			return
+		}
+		queue, err := parseQosValue(m, "queue", qosQueues)
+		if err != nil {
+			mapErr = err
+			return
+		}
+		a := map[string]any{"cos": cos, "queue": queue}
+		for k, v := range arg {
+			a[k] = v
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidQosCosQueueSet, a); err != nil {
+			mapErr = err
+			return
+		}
+		applied = true
+	})
+	if mapErr != nil {
+		return mapErr
+	}
+	if !applied {
+		return server.MissingElement("map")
+	}
+	return nil
+}
+
+// parseQosValue reads a required numeric child in [0, max] (queues are
+// 1-based: [1, max]).
+func parseQosValue(e *server.Element, tag string, max int) (int, error) {
+	s := e.ChildText(tag)
+	if s == "" {
+		return 0, server.MissingElement(tag)
+	}
+	lo := 0
+	if tag == "queue" {
+		lo = 1
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > max {
+		return 0, server.InvalidValue("<%s> must be %d..%d, got %q", tag, lo, max, s)
+	}
+	return v, nil
+}
+
+// miyagiCosQueueMap mirrors the qos.cos_queue.get return payload.
+type miyagiCosQueueMap struct {
+	Global []miyagiCosQueueEntry `json:"global"`
+	Ports  []struct {
+		Ifname string                `json:"ifname"`
+		Map    []miyagiCosQueueEntry `json:"map"`
+	} `json:"ports"`
+}
+
+type miyagiCosQueueEntry struct {
+	Cos   int `json:"cos"`
+	Queue int `json:"queue"`
+}
+
+// getQos returns the active QoS tables: the global CoS-to-queue map and
+// any per-port overrides.
+func getQos(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidQosCosQueueGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var table miyagiCosQueueMap
+	if err := json.Unmarshal(ret, &table); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidQosCosQueueGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<qos xmlns=%q>`, NSQos)
+	b.WriteString("<cos-queue-map>")
+	writeCosQueueEntries(&b, table.Global)
+	b.WriteString("</cos-queue-map>")
+	for _, p := range table.Ports {
+		fmt.Fprintf(&b, "<cos-queue-map><name>%s</name>", server.EscapeXML(p.Ifname))
+		writeCosQueueEntries(&b, p.Map)
+		b.WriteString("</cos-queue-map>")
+	}
+	if ctx.HasFeature(uidQosDefPrioGet) {
+		ret, err := ctx.Miyagi.Call(ctx, uidQosDefPrioGet, nil)
+		if err != nil {
+			return "", err
+		}
+		var prios []struct {
+			Ifname   string `json:"ifname"`
+			Priority int    `json:"priority"`
+		}
+		if err := json.Unmarshal(ret, &prios); err != nil {
+			return "", server.OperationFailed("decode %s reply: %v", uidQosDefPrioGet, err)
+		}
+		for _, p := range prios {
+			fmt.Fprintf(&b, "<default-priority><name>%s</name><priority>%d</priority></default-priority>",
+				server.EscapeXML(p.Ifname), p.Priority)
+		}
+	}
+	b.WriteString("</qos>")
+	return b.String(), nil
+}
+
+func writeCosQueueEntries(b *strings.Builder, entries []miyagiCosQueueEntry) {
+	for _, e := range entries {
+		fmt.Fprintf(b, "<map><cos>%d</cos><queue>%d</queue></map>", e.Cos, e.Queue)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSRefresh is the namespace for the cache maintenance RPC. Handlers
+// keep a few tables between RPCs (the ifindex map, rate baselines, the
+// measured clock drift) and the server caches get replies for
+// namespaces with a cache_ttl; after an out-of-band CLI change those
+// can lag the device until their next natural refresh. <refresh-cache>
+// forces the re-fetch immediately, optionally scoped to one named
+// cache or to one namespace's cached get replies.
+const NSRefresh = "yang:refresh"
+
+// cacheRefresher re-fetches or resets one named handler-side cache.
+type cacheRefresher struct {
+	name    string
+	refresh func(ctx *server.Context) error
+}
+
+// cacheRefreshers lists every cache the handlers keep between RPCs, in
+// the order they are refreshed.
+var cacheRefreshers = []cacheRefresher{
+	{"ifindex", refreshIfIndexTable},
+	{"interface-rates", resetRateBaselines},
+	{"clock-drift", resetClockDrift},
+	{"get-replies", flushGetReplies},
+}
+
+func refreshHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSRefresh,
+		RPC:       refreshRPC,
+	}
+}
+
+// refreshRPC serves <refresh-cache>. Without a <name> child every
+// registered cache is refreshed.
+func refreshRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "refresh-cache" {
+		return "", server.OperationNotSupported("unknown operation <%s> in %s", op.Local(), NSRefresh)
+	}
+	name := op.ChildText("name")
+	var refreshed []string
+	for _, c := range cacheRefreshers {
+		if name != "" && c.name != name {
+			continue
+		}
+		if err := c.refresh(ctx); err != nil {
+			return "", err
+		}
+		refreshed = append(refreshed, c.name)
+	}
+	if len(refreshed) == 0 {
+		// A registered namespace scopes the flush to that namespace's
+		// cached get replies, leaving the other caches alone.
+		if ctx.Server.Handler(name) == nil {
+			return "", server.InvalidValue("unknown cache %q; known caches: %s, or a registered namespace", name, strings.Join(cacheNames(), ", "))
+		}
+		ctx.Server.InvalidateGetCache(name)
+		refreshed = append(refreshed, name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<refreshed xmlns=%q>`, NSRefresh)
+	for _, n := range refreshed {
+		fmt.Fprintf(&b, "<cache>%s</cache>", n)
+	}
+	b.WriteString("</refreshed>")
+	return b.String(), nil
+}
+
+func cacheNames() []string {
+	names := make([]string, len(cacheRefreshers))
+	for i, c := range cacheRefreshers {
+		names[i] = c.name
+	}
+	return names
+}
+
+// refreshIfIndexTable re-fetches the interface name <-> ifIndex table,
+// picking up ports and LAGs created outside the gateway.
+func refreshIfIndexTable(ctx *server.Context) error {
+	if !ctx.HasFeature(uidIfIndexGet) {
+		return nil
+	}
+	ifIndexCache.Lock()
+	defer ifIndexCache.Unlock()
+	_, err := refreshIfIndexCache(ctx)
+	return err
+}
+
+// resetRateBaselines drops the previous counter sample so the rate
+// poller re-establishes its baselines on the next tick, instead of
+// computing a rate across a counter reset.
+func resetRateBaselines(ctx *server.Context) error {
+	rateState.Lock()
+	defer rateState.Unlock()
+	rateState.last = nil
+	rateState.lastAt = time.Time{}
+	rateState.rates = nil
+	return nil
+}
+
+// flushGetReplies clears the server's cached get replies so the next
+// read of every namespace reaches the device again.
+func flushGetReplies(ctx *server.Context) error {
+	ctx.Server.InvalidateGetCache("")
+	return nil
+}
+
+// resetClockDrift discards the measured offset; the drift watcher
+// re-measures on its next poll.
+func resetClockDrift(ctx *server.Context) error {
+	driftState.Lock()
+	defer driftState.Unlock()
+	driftState.measured = false
+	driftState.drift = 0
+	return nil
+}
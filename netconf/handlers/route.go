@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSRoute is the namespace for static route configuration.
+const NSRoute = "yang:route"
+
+const (
+	uidRouteAdd        = "ip.route.add"
+	uidRouteDel        = "ip.route.delete"
+	uidBfdSessionsGet  = "bfd.sessions.get"
+	bfdMinIntervalMin  = 50    // milliseconds
+	bfdMinIntervalMax  = 10000 // milliseconds
+	bfdMultiplierLimit = 50
+)
+
+// RouteNextHop is one next hop of a static route: either a gateway
+// address or an exit interface (for point-to-point links), never both.
+type RouteNextHop struct {
+	Gateway   string
+	Interface string
+}
+
+// RouteData is the parsed form of one <route> config item. A route may
+// carry several next hops (ECMP); each hop becomes its own Miyagi call.
+type RouteData struct {
+	Prefix   string
+	Mask     string
+	NextHops []RouteNextHop
+	Distance int // administrative distance, 0 = device default
+	Metric   int // 0 = device default
+	Delete   bool
+
+	// BFD, when true, ties the route's next hops to BFD sessions so
+	// a dead gateway withdraws the route quickly. Intervals are in
+	// milliseconds; zero means device default.
+	BFD            bool
+	BFDMinInterval int
+	BFDMultiplier  int
+}
+
+func routeHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSRoute,
+		Get:       getBfdSessions,
+		Edit:      editRoute,
+	}
+}
+
+// ParseRoute extracts RouteData from a <route> item.
+func ParseRoute(item *server.Element) (*RouteData, error) {
+	data := &RouteData{
+		Prefix: item.ChildText("prefix"),
+		Mask:   item.ChildText("mask"),
+		Delete: item.Attr("operation") == "delete" || item.ChildText("operation") == "delete",
+	}
+	if data.Prefix == "" || data.Mask == "" {
+		return nil, server.InvalidValue("route needs both <prefix> and <mask>")
+	}
+	if err := checkAddress("/route/prefix", data.Prefix); err != nil {
+		return nil, err
+	}
+	if err := checkMask("/route/mask", data.Mask); err != nil {
+		return nil, err
+	}
+	var parseErr error
+	item.EachChild("next-hop", func(nh *server.Element) {
+		hop := RouteNextHop{
+			Gateway:   nh.ChildText("gateway"),
+			Interface: nh.ChildText("interface"),
+		}
+		// A bare <next-hop>10.0.0.1</next-hop> is a gateway address.
+		if hop.Gateway == "" && hop.Interface == "" {
+			hop.Gateway = trimmedText(nh)
+		}
+		if err := validateNextHop(data.Prefix, hop); err != nil {
+			parseErr = err
+			return
+		}
+		data.NextHops = append(data.NextHops, hop)
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if len(data.NextHops) == 0 && !data.Delete {
+		return nil, server.InvalidValue("route %s needs at least one <next-hop>", data.Prefix)
+	}
+	var err error
+	if data.Distance, err = parseRouteUint(item, "distance", 255); err != nil {
+		return nil, err
+	}
+	if data.Metric, err = parseRouteUint(item, "metric", 65535); err != nil {
+		return nil, err
+	}
+	if bfd := item.Child("bfd"); bfd != nil {
+		if err := parseRouteBfd(data, bfd); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// parseRouteBfd reads the optional <bfd> block. BFD needs a peer
+// address to run its session against, so every next hop must be a
+// gateway, not an exit interface.
+func parseRouteBfd(data *RouteData, bfd *server.Element) error {
+	data.BFD = true
+	for _, hop := range data.NextHops {
+		if hop.Interface != "" {
+			return server.InvalidValue("route %s: bfd requires gateway next-hops, not interface %q", data.Prefix, hop.Interface)
+		}
+	}
+	if s := bfd.ChildText("min-interval"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < bfdMinIntervalMin || v > bfdMinIntervalMax {
+			return server.InvalidValue("route %s: bfd min-interval must be %d..%d ms, got %q",
+				data.Prefix, bfdMinIntervalMin, bfdMinIntervalMax, s)
+		}
+		data.BFDMinInterval = v
+	}
+	if s := bfd.ChildText("multiplier"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > bfdMultiplierLimit {
+			return server.InvalidValue("route %s: bfd multiplier must be 1..%d, got %q",
+				data.Prefix, bfdMultiplierLimit, s)
+		}
+		data.BFDMultiplier = v
+	}
+	return nil
+}
+
+func validateNextHop(prefix string, hop RouteNextHop) error {
+	if hop.Gateway != "" && hop.Interface != "" {
+		return server.InvalidValue("route %s: next-hop gateway and interface are mutually exclusive", prefix)
+	}
+	if hop.Gateway == "" && hop.Interface == "" {
+		return server.InvalidValue("route %s: next-hop needs a gateway or an interface", prefix)
+	}
+	if hop.Gateway != "" {
+		return checkAddress("/route/next-hop/gateway", hop.Gateway)
+	}
+	return nil
+}
+
+func trimmedText(e *server.Element) string {
+	if len(e.Children) > 0 {
+		return ""
+	}
+	return strings.TrimSpace(e.Text)
+}
+
+// parseRouteUint reads an optional numeric child in [1, max].
+func parseRouteUint(item *server.Element, name string, max int) (int, error) {
+	s := item.ChildText(name)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 1 || v > max {
+		return 0, server.InvalidValue("route %s must be 1..%d, got %q", name, max, s)
+	}
+	return v, nil
+}
+
+// miyagiRouteArg builds the extended argument object for the route UIDs.
+func miyagiRouteArg(data *RouteData, hop *RouteNextHop) map[string]any {
+	arg := map[string]any{
+		"prefix": data.Prefix,
+		"mask":   data.Mask,
+	}
+	if hop != nil {
+		if hop.Gateway != "" {
+			arg["next_hop"] = hop.Gateway
+		}
+		if hop.Interface != "" {
+			arg["ifname"] = hop.Interface
+		}
+	}
+	if data.Distance != 0 {
+		arg["distance"] = data.Distance
+	}
+	if data.Metric != 0 {
+		arg["metric"] = data.Metric
+	}
+	if data.BFD {
+		arg["bfd"] = true
+		if data.BFDMinInterval != 0 {
+			arg["bfd_min_interval"] = data.BFDMinInterval
+		}
+		if data.BFDMultiplier != 0 {
+			arg["bfd_multiplier"] = data.BFDMultiplier
+		}
+	}
+	return arg
+}
+
+// editRoute applies one <route> item. Adds issue one ip.route.add per
+// next hop. A delete with next hops removes only those hops; a delete
+// without any removes the whole route.
+func editRoute(ctx *server.Context, item *server.Element) error {
+	data, err := ParseRoute(item)
+	if err != nil {
+		return err
+	}
+	uid := uidRouteAdd
+	if data.Delete {
+		uid = uidRouteDel
+		if len(data.NextHops) == 0 {
+			_, err := ctx.Miyagi.Call(ctx, uid, miyagiRouteArg(data, nil))
+			return err
+		}
+	}
+	for i := range data.NextHops {
+		if _, err := ctx.Miyagi.Call(ctx, uid, miyagiRouteArg(data, &data.NextHops[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// miyagiBfdSession mirrors one entry of the bfd.sessions.get return
+// payload.
+type miyagiBfdSession struct {
+	Peer        string `json:"peer"`
+	Local       string `json:"local"`
+	State       string `json:"state"` // up, down, admin-down, init
+	Uptime      string `json:"uptime"`
+	Diagnostic  string `json:"diag"`
+	MinInterval int    `json:"min_interval"`
+	Multiplier  int    `json:"multiplier"`
+}
+
+// getBfdSessions returns the state of every BFD session the device is
+// running for its static routes.
+func getBfdSessions(ctx *server.Context, filter *server.Element) (string, error) {
+	if !ctx.HasFeature(uidBfdSessionsGet) {
+		return fmt.Sprintf(`<bfd-sessions xmlns=%q/>`, NSRoute), nil
+	}
+	ret, err := ctx.Miyagi.Call(ctx, uidBfdSessionsGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var sessions []miyagiBfdSession
+	if err := json.Unmarshal(ret, &sessions); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidBfdSessionsGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<bfd-sessions xmlns=%q>`, NSRoute)
+	for _, s := range sessions {
+		b.WriteString("<session>")
+		fmt.Fprintf(&b, "<peer>%s</peer><local>%s</local><state>%s</state>",
+			server.EscapeXML(s.Peer), server.EscapeXML(s.Local), server.EscapeXML(s.State))
+		if s.Uptime != "" {
+			fmt.Fprintf(&b, "<uptime>%s</uptime>", server.EscapeXML(s.Uptime))
+		}
+		if s.Diagnostic != "" {
+			fmt.Fprintf(&b, "<diagnostic>%s</diagnostic>", server.EscapeXML(s.Diagnostic))
+		}
+		if s.MinInterval != 0 {
+			fmt.Fprintf(&b, "<min-interval>%d</min-interval><multiplier>%d</multiplier>", s.MinInterval, s.Multiplier)
+		}
+		b.WriteString("</session>")
+	}
+	b.WriteString("</bfd-sessions>")
+	return b.String(), nil
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSSchedule is the namespace for scheduled configuration applies.
+const NSSchedule = "yang:schedule"
+
+// scheduledJob is one pending apply.
+type scheduledJob struct {
+	ID     uint64
+	At     time.Time
+	Config *server.Element
+	timer  *time.Timer
+	// Result is empty while pending, then "applied" or the error text.
+	Result string
+}
+
+// scheduler keeps the pending and completed jobs.
+var scheduler struct {
+	sync.Mutex
+	nextID uint64
+	jobs   map[uint64]*scheduledJob
+}
+
+func scheduleHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSSchedule,
+		RPC:       scheduleRPC,
+	}
+}
+
+// scheduleRPC serves <apply-at> (an <at> RFC 3339 time plus a <config>
+// payload), <list> and <cancel><id>. The payload is validated for a
+// registered handler per item at schedule time and applied when the
+// timer fires.
+func scheduleRPC(ctx *server.Context, op *server.Element) (string, error) {
+	switch op.Local() {
+	case "apply-at":
+		return scheduleApplyAt(ctx, op)
+	case "list":
+		return scheduleList(), nil
+	case "cancel":
+		id, err := strconv.ParseUint(op.ChildText("id"), 10, 64)
+		if err != nil {
+			return "", server.InvalidValue("cancel needs a numeric <id>")
+		}
+		scheduler.Lock()
+		defer scheduler.Unlock()
+		job, ok := scheduler.jobs[id]
+		if !ok || job.Result != "" {
+			return "", server.InvalidValue("no pending job %d", id)
+		}
+		job.timer.Stop()
+		delete(scheduler.jobs, id)
+		return "<ok/>", nil
+	default:
+		return "", server.OperationNotSupported("unknown schedule rpc <%s>", op.Local())
+	}
+}
+
+func scheduleApplyAt(ctx *server.Context, op *server.Element) (string, error) {
+	at, err := time.Parse(time.RFC3339, op.ChildText("at"))
+	if err != nil {
+		return "", server.InvalidValue("<at> must be an RFC 3339 time: %v", err)
+	}
+	delay := time.Until(at)
+	if delay <= 0 {
+		return "", server.InvalidValue("<at> is in the past")
+	}
+	config := op.Child("config")
+	if config == nil || len(config.Children) == 0 {
+		return "", server.MissingElement("config")
+	}
+	for i := range config.Children {
+		ns := config.Children[i].Namespace()
+		if h := ctx.Server.Handler(ns); h == nil || h.Edit == nil {
+			return "", server.OperationNotSupported("no edit handler for namespace %q", ns)
+		}
+	}
+
+	srv := ctx.Server
+	scheduler.Lock()
+	defer scheduler.Unlock()
+	if scheduler.jobs == nil {
+		scheduler.jobs = make(map[uint64]*scheduledJob)
+	}
+	scheduler.nextID++
+	job := &scheduledJob{ID: scheduler.nextID, At: at, Config: config}
+	job.timer = time.AfterFunc(delay, func() {
+		result := "applied"
+		if err := srv.ApplyConfig(srv.BackgroundContext(), job.Config); err != nil {
+			result = err.Error()
+		}
+		scheduler.Lock()
+		job.Result = result
+		scheduler.Unlock()
+		// The session that scheduled the job is often long gone by the
+		// time it fires, so the outcome is pushed on the CONFIG stream
+		// rather than only stored for <list> polling.
+		srv.Notify("CONFIG", fmt.Sprintf(
+			"<scheduled-apply xmlns=%q><id>%d</id><at>%s</at><result>%s</result></scheduled-apply>",
+			NSSchedule, job.ID, job.At.UTC().Format(time.RFC3339), server.EscapeXML(result)))
+	})
+	scheduler.jobs[job.ID] = job
+	return fmt.Sprintf("<job-id>%d</job-id>", job.ID), nil
+}
+
+func scheduleList() string {
+	scheduler.Lock()
+	defer scheduler.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<jobs xmlns=%q>`, NSSchedule)
+	for _, job := range scheduler.jobs {
+		state := job.Result
+		if state == "" {
+			state = "pending"
+		}
+		fmt.Fprintf(&b, "<job><id>%d</id><at>%s</at><state>%s</state></job>",
+			job.ID, job.At.UTC().Format(time.RFC3339), server.EscapeXML(state))
+	}
+	b.WriteString("</jobs>")
+	return b.String()
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSSchema is the namespace for the completion RPC, which describes
+// what payloads the config namespaces accept so generic UIs can build
+// forms without hard-coding every handler's element layout.
+const NSSchema = "yang:schema"
+
+func schemaHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSSchema,
+		RPC:       schemaRPC,
+	}
+}
+
+// schemaNode describes one element a namespace accepts: its name, the
+// value type for leaves ("" for containers), the allowed enum values
+// where the handler validates against a fixed set, and the children
+// for containers. There are no machine-readable YANG models on the
+// device, so this table is maintained by hand alongside the handlers
+// it describes.
+type schemaNode struct {
+	Name     string
+	Type     string
+	Enum     []string
+	Children []schemaNode
+}
+
+var onOff = []string{"true", "false"}
+
+// schemaTree covers the most commonly driven config namespaces. New
+// handlers should add their shapes here when UIs need to drive them.
+var schemaTree = map[string][]schemaNode{
+	NSVlan: {
+		{Name: "vlan", Children: []schemaNode{
+			{Name: "id", Type: "uint16 (1..4094)"},
+			{Name: "name", Type: "string"},
+			{Name: "operation", Type: "string", Enum: []string{"delete"}},
+		}},
+		{Name: "flood-control", Children: []schemaNode{
+			{Name: "vlan", Type: "uint16 (1..4094)"},
+			{Name: "unknown-unicast", Type: "string", Enum: []string{"block", "flood"}},
+			{Name: "unknown-multicast", Type: "string", Enum: []string{"block", "flood"}},
+		}},
+	},
+	NSPort: {
+		{Name: "port", Children: []schemaNode{
+			{Name: "name", Type: "string"},
+			{Name: "speed", Type: "string"},
+			{Name: "duplex", Type: "string", Enum: []string{"full", "half"}},
+			{Name: "flow-control", Type: "string", Enum: []string{"on", "off", "auto"}},
+			{Name: "shutdown", Type: "boolean", Enum: onOff},
+			{Name: "auto-negotiation", Type: "boolean", Enum: onOff},
+			{Name: "rate-limit", Children: []schemaNode{
+				{Name: "ingress", Children: rateLimitSchema()},
+				{Name: "egress", Children: rateLimitSchema()},
+			}},
+		}},
+	},
+	NSRoute: {
+		{Name: "route", Children: []schemaNode{
+			{Name: "prefix", Type: "ipv4-address"},
+			{Name: "mask", Type: "ipv4-address"},
+			{Name: "gateway", Type: "ipv4-address"},
+			{Name: "interface", Type: "string"},
+			{Name: "operation", Type: "string", Enum: []string{"delete"}},
+			{Name: "bfd", Children: []schemaNode{
+				{Name: "min-interval", Type: "uint32 (50..10000 ms)"},
+				{Name: "multiplier", Type: "uint8 (1..50)"},
+			}},
+		}},
+	},
+	NSClock: {
+		{Name: "clock", Children: []schemaNode{
+			{Name: "time", Type: "date-and-time (RFC 3339)"},
+			{Name: "timezone", Type: "string (IANA zone name)"},
+			{Name: "dst", Children: []schemaNode{
+				{Name: "start", Type: "string"},
+				{Name: "end", Type: "string"},
+				{Name: "offset", Type: "string"},
+			}},
+		}},
+	},
+	NSBanner: {
+		{Name: "banner", Children: []schemaNode{
+			{Name: "login", Type: "string (max 2000 chars)"},
+			{Name: "motd", Type: "string (max 2000 chars)"},
+		}},
+	},
+	NSStp: {
+		{Name: "port", Children: []schemaNode{
+			{Name: "name", Type: "string"},
+			{Name: "loop-guard", Type: "boolean", Enum: onOff},
+			{Name: "bpdu-filter", Type: "boolean", Enum: onOff},
+			{Name: "bpdu-guard", Type: "boolean", Enum: onOff},
+		}},
+	},
+}
+
+func rateLimitSchema() []schemaNode {
+	return []schemaNode{
+		{Name: "rate", Type: "uint32 (kbps)"},
+		{Name: "burst", Type: "uint16 (1..16384 KB)"},
+	}
+}
+
+// schemaRPC serves <completions>: given a slash-separated path rooted
+// at a namespace (e.g. "yang:port/port/rate-limit"), it returns the
+// elements allowed at that point; with no <path> it lists the
+// namespaces the table covers.
+func schemaRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "completions" {
+		return "", server.OperationNotSupported("unknown RPC <%s> in %s", op.Local(), NSSchema)
+	}
+	path := op.ChildText("path")
+	var b strings.Builder
+	fmt.Fprintf(&b, "<completions><path>%s</path>", server.EscapeXML(path))
+	if path == "" {
+		for _, ns := range sortedSchemaNamespaces() {
+			fmt.Fprintf(&b, "<child><name>%s</name></child>", server.EscapeXML(ns))
+		}
+		b.WriteString("</completions>")
+		return b.String(), nil
+	}
+	segs := strings.Split(path, "/")
+	nodes, ok := schemaTree[segs[0]]
+	if !ok {
+		return "", server.InvalidValue("no schema information for namespace %q", segs[0])
+	}
+	for _, seg := range segs[1:] {
+		next := findNode(nodes, seg)
+		if next == nil {
+			return "", server.InvalidValue("no element %q under %q", seg, path)
+		}
+		nodes = next.Children
+	}
+	for _, n := range nodes {
+		fmt.Fprintf(&b, "<child><name>%s</name>", server.EscapeXML(n.Name))
+		if n.Type != "" {
+			fmt.Fprintf(&b, "<type>%s</type>", server.EscapeXML(n.Type))
+		}
+		if len(n.Children) > 0 {
+			b.WriteString("<type>container</type>")
+		}
+		for _, e := range n.Enum {
+			fmt.Fprintf(&b, "<enum>%s</enum>", server.EscapeXML(e))
+		}
+		b.WriteString("</child>")
+	}
+	b.WriteString("</completions>")
+	return b.String(), nil
+}
+
+func findNode(nodes []schemaNode, name string) *schemaNode {
+	for i := range nodes {
+		if nodes[i].Name == name {
+			return &nodes[i]
+		}
+	}
+	return nil
+}
+
+func sortedSchemaNamespaces() []string {
+	out := make([]string, 0, len(schemaTree))
+	for ns := range schemaTree {
+		out = append(out, ns)
+	}
+	sort.Strings(out)
+	return out
+}
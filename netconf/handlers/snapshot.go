@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSSnapshot is the namespace for named configuration snapshots.
+const NSSnapshot = "yang:snapshot"
+
+const (
+	uidSnapshotSave     = "config.snapshot.save"
+	uidSnapshotRollback = "config.snapshot.rollback"
+	uidSnapshotDelete   = "config.snapshot.delete"
+	uidSnapshotList     = "config.snapshot.list"
+)
+
+func snapshotHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSSnapshot,
+		RPC:       snapshotRPC,
+	}
+}
+
+// snapshotRPC serves <save>, <rollback>, <delete> (each taking a
+// <name>) and <list>. Save captures the running configuration under
+// the given name; rollback restores it.
+func snapshotRPC(ctx *server.Context, op *server.Element) (string, error) {
+	switch op.Local() {
+	case "list":
+		ret, err := ctx.Miyagi.Call(ctx, uidSnapshotList, nil)
+		if err != nil {
+			return "", err
+		}
+		var snaps []struct {
+			Name string `json:"name"`
+			Time string `json:"time"`
+		}
+		if err := json.Unmarshal(ret, &snaps); err != nil {
+			return "", server.OperationFailed("decode %s reply: %v", uidSnapshotList, err)
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, `<snapshots xmlns=%q>`, NSSnapshot)
+		for _, s := range snaps {
+			fmt.Fprintf(&b, "<snapshot><name>%s</name><time>%s</time></snapshot>",
+				server.EscapeXML(s.Name), server.EscapeXML(s.Time))
+		}
+		b.WriteString("</snapshots>")
+		return b.String(), nil
+	case "save", "rollback", "delete":
+		name := op.ChildText("name")
+		if name == "" {
+			return "", server.MissingElement("name")
+		}
+		uid := map[string]string{
+			"save":     uidSnapshotSave,
+			"rollback": uidSnapshotRollback,
+			"delete":   uidSnapshotDelete,
+		}[op.Local()]
+		if _, err := ctx.Miyagi.Call(ctx, uid, map[string]any{"name": name}); err != nil {
+			return "", err
+		}
+		return "<ok/>", nil
+	default:
+		return "", server.OperationNotSupported("unknown snapshot rpc <%s>", op.Local())
+	}
+}
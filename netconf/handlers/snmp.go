@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSSnmp is the namespace for the SNMP OID bridge.
+const NSSnmp = "yang:snmp"
+
+// snmpAgent holds how to reach the device's SNMP agent. The agent runs
+// on the switch itself, so the default points at localhost; both
+// fields are overridable from the command line.
+var snmpAgent = struct {
+	target    string
+	port      uint16
+	community string
+}{"127.0.0.1", 161, "public"}
+
+// snmpTimeout bounds one agent exchange.
+const snmpTimeout = 5 * time.Second
+
+// SetSNMPAgent overrides the agent address ("host" or "host:port")
+// and community the OID bridge queries.
+func SetSNMPAgent(target, community string) error {
+	if host, portStr, err := net.SplitHostPort(target); err == nil {
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			return fmt.Errorf("bad SNMP agent port %q", portStr)
+		}
+		snmpAgent.target, snmpAgent.port = host, uint16(port)
+	} else {
+		snmpAgent.target = target
+	}
+	if community != "" {
+		snmpAgent.community = community
+	}
+	return nil
+}
+
+func snmpHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSSnmp,
+		RPC:       snmpRPC,
+	}
+}
+
+// snmpRPC serves <get-oid> with one or more <oid> children, querying
+// the device's SNMP agent directly and returning value and type per
+// OID. The agent speaks SNMP natively, so the bridge talks to it
+// rather than detouring through the Miyagi daemon.
+func snmpRPC(ctx *server.Context, op *server.Element) (string, error) {
+	if op.Local() != "get-oid" {
+		return "", server.OperationNotSupported("unknown snmp rpc <%s>", op.Local())
+	}
+	var oids []string
+	var oidErr error
+	op.EachChild("oid", func(e *server.Element) {
+		oid := strings.TrimSpace(e.Text)
+		if !validOID(oid) {
+			oidErr = server.InvalidValue("%q is not a numeric OID", oid)
+			return
+		}
+		oids = append(oids, oid)
+	})
+	if oidErr != nil {
+		return "", oidErr
+	}
+	if len(oids) == 0 {
+		return "", server.MissingElement("oid")
+	}
+	agent := &gosnmp.GoSNMP{
+		Target:    snmpAgent.target,
+		Port:      snmpAgent.port,
+		Community: snmpAgent.community,
+		Version:   gosnmp.Version2c,
+		Timeout:   snmpTimeout,
+		Retries:   1,
+	}
+	if err := agent.Connect(); err != nil {
+		return "", server.OperationFailed("snmp agent %s: %v", snmpAgent.target, err)
+	}
+	defer agent.Conn.Close()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<oids xmlns=%q>`, NSSnmp)
+	// The protocol caps one request at gosnmp.MaxOids variables, so
+	// larger requests go out in chunks.
+	for len(oids) > 0 {
+		chunk := oids
+		if len(chunk) > gosnmp.MaxOids {
+			chunk = chunk[:gosnmp.MaxOids]
+		}
+		oids = oids[len(chunk):]
+		pkt, err := agent.Get(chunk)
+		if err != nil {
+			return "", server.OperationFailed("snmp get: %v", err)
+		}
+		for _, v := range pkt.Variables {
+			fmt.Fprintf(&b, "<oid><name>%s</name><type>%s</type><value>%s</value></oid>",
+				server.EscapeXML(strings.TrimPrefix(v.Name, ".")),
+				server.EscapeXML(v.Type.String()),
+				server.EscapeXML(snmpValueString(v)))
+		}
+	}
+	b.WriteString("</oids>")
+	return b.String(), nil
+}
+
+// snmpValueString renders one variable binding's value as text.
+func snmpValueString(v gosnmp.SnmpPDU) string {
+	switch val := v.Value.(type) {
+	case []byte:
+		return string(val)
+	case string:
+		return val
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// validOID accepts dotted numeric OIDs like "1.3.6.1.2.1.1.1.0".
+func validOID(oid string) bool {
+	if oid == "" {
+		return false
+	}
+	for _, part := range strings.Split(oid, ".") {
+		if part == "" {
+			return false
+		}
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
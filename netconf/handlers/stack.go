@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSStack is the namespace for stack status.
+const NSStack = "yang:stack"
+
+const uidStackGet = "stack.get"
+
+// ParseUnitPort splits a unit-aware port name like "2/ge5" into the
+// stack unit and the local port name. Names without a unit prefix
+// belong to unit 1 (standalone or stack master).
+func ParseUnitPort(name string) (unit int, port string, err error) {
+	head, rest, ok := strings.Cut(name, "/")
+	if !ok {
+		return 1, name, nil
+	}
+	unit, convErr := strconv.Atoi(head)
+	if convErr != nil || unit < 1 {
+		return 0, "", server.InvalidValue("bad unit in port name %q", name)
+	}
+	if rest == "" {
+		return 0, "", server.InvalidValue("port name %q has no port after the unit", name)
+	}
+	return unit, rest, nil
+}
+
+// miyagiStackUnit mirrors one entry of the stack.get return payload.
+type miyagiStackUnit struct {
+	Unit  int    `json:"unit"`
+	Role  string `json:"role"` // "master", "backup" or "member"
+	State string `json:"state"`
+	Model string `json:"model"`
+}
+
+func stackHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSStack,
+		Get:       getStack,
+	}
+}
+
+// getStack returns every stack unit with its role and state.
+func getStack(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidStackGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var units []miyagiStackUnit
+	if err := json.Unmarshal(ret, &units); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidStackGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<stack xmlns=%q>`, NSStack)
+	for _, u := range units {
+		fmt.Fprintf(&b, "<unit><id>%d</id><role>%s</role><state>%s</state><model>%s</model></unit>",
+			u.Unit, server.EscapeXML(u.Role), server.EscapeXML(u.State), server.EscapeXML(u.Model))
+	}
+	b.WriteString("</stack>")
+	return b.String(), nil
+}
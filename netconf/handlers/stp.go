@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSGetStpState is the namespace for STP operational state.
+const NSGetStpState = "yang:get_stp_state"
+
+// NSStp is the namespace for per-port STP protection settings.
+const NSStp = "yang:stp"
+
+const (
+	uidStpStateGet      = "stp.state.get"
+	uidStpTopoChngGet   = "stp.topology_change.get"
+	uidStpLoopGuardSet  = "stp.port.loop_guard.set"
+	uidStpBpduFilterSet = "stp.port.bpdu_filter.set"
+	uidStpBpduGuardSet  = "stp.port.bpdu_guard.set"
+	stpWatcherInterval  = 10 * time.Second
+)
+
+func stpStateHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSGetStpState,
+		Get:       getStpState,
+	}
+}
+
+func stpHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSStp,
+		Edit:      editStpPort,
+	}
+}
+
+// editStpPort applies per-port STP protection settings: <loop-guard>,
+// <bpdu-filter> and <bpdu-guard>, each "true" or "false". BPDU filter
+// and BPDU guard cannot both be enabled: the filter stops the BPDUs
+// the guard would trip on.
+func editStpPort(ctx *server.Context, item *server.Element) error {
+	name, err := InterfaceRef(ctx, item)
+	if err != nil {
+		return err
+	}
+	flags := map[string]*bool{
+		"loop-guard":  nil,
+		"bpdu-filter": nil,
+		"bpdu-guard":  nil,
+	}
+	for tag := range flags {
+		if c := item.Child(tag); c != nil {
+			v := strings.TrimSpace(c.Text) == "true"
+			flags[tag] = &v
+		}
+	}
+	if flags["loop-guard"] == nil && flags["bpdu-filter"] == nil && flags["bpdu-guard"] == nil {
+		return server.MissingElement("loop-guard, bpdu-filter or bpdu-guard")
+	}
+	if f, g := flags["bpdu-filter"], flags["bpdu-guard"]; f != nil && g != nil && *f && *g {
+		return server.InvalidValue("port %s: bpdu-filter and bpdu-guard are mutually exclusive; the filter discards the BPDUs the guard reacts to", name)
+	}
+	for tag, uid := range map[string]string{
+		"loop-guard":  uidStpLoopGuardSet,
+		"bpdu-filter": uidStpBpduFilterSet,
+		"bpdu-guard":  uidStpBpduGuardSet,
+	} {
+		v := flags[tag]
+		if v == nil {
+			continue
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uid, map[string]any{"ifname": name, "enable": *v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// miyagiStpState mirrors the stp.state.get return payload.
+type miyagiStpState struct {
+	Protocol string `json:"protocol"` // "stp", "rstp" or "mstp"
+	BridgeID string `json:"bridge_id"`
+	RootID   string `json:"root_id"`
+	RootPort string `json:"root_port"`
+	RootCost int    `json:"root_cost"`
+	Ports    []struct {
+		Ifname string `json:"ifname"`
+		Role   string `json:"role"`  // root, designated, alternate, backup, disabled
+		State  string `json:"state"` // forwarding, learning, discarding, blocking
+		Cost   int    `json:"cost"`
+	} `json:"ports"`
+}
+
+// getStpState returns the spanning-tree view of the bridge: who the
+// root is, which port leads to it, and the role and state of every
+// participating port.
+func getStpState(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidStpStateGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var st miyagiStpState
+	if err := json.Unmarshal(ret, &st); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidStpStateGet, err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<stp-state xmlns=%q>`, NSGetStpState)
+	fmt.Fprintf(&b, "<protocol>%s</protocol>", server.EscapeXML(st.Protocol))
+	fmt.Fprintf(&b, "<bridge-id>%s</bridge-id><root-id>%s</root-id>",
+		server.EscapeXML(st.BridgeID), server.EscapeXML(st.RootID))
+	if st.RootPort != "" {
+		fmt.Fprintf(&b, "<root-port>%s</root-port><root-cost>%d</root-cost>",
+			server.EscapeXML(st.RootPort), st.RootCost)
+	}
+	for _, p := range st.Ports {
+		fmt.Fprintf(&b, "<port><name>%s</name><role>%s</role><state>%s</state><cost>%d</cost></port>",
+			server.EscapeXML(p.Ifname), server.EscapeXML(p.Role), server.EscapeXML(p.State), p.Cost)
+	}
+	b.WriteString("</stp-state>")
+	return b.String(), nil
+}
+
+// miyagiStpTopoChange mirrors the stp.topology_change.get return
+// payload: a monotonically increasing change counter plus the port
+// that triggered the last change.
+type miyagiStpTopoChange struct {
+	Count    uint64 `json:"count"`
+	LastPort string `json:"last_port"`
+}
+
+// WatchSTPTopology polls the STP topology-change counter and emits a
+// <stp-topology-change> notification whenever it advances. It returns
+// immediately on devices without the counter and when the server shuts
+// down.
+func WatchSTPTopology(srv *server.Server) {
+	ctx := srv.BackgroundContext()
+	if !ctx.HasFeature(uidStpTopoChngGet) {
+		return
+	}
+	var last uint64
+	seeded := false
+	ticker := time.NewTicker(stpWatcherInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ret, err := ctx.Miyagi.Call(ctx, uidStpTopoChngGet, nil)
+		if err != nil {
+			continue
+		}
+		var tc miyagiStpTopoChange
+		if err := json.Unmarshal(ret, &tc); err != nil {
+			continue
+		}
+		if seeded && tc.Count > last {
+			srv.Notify("LINK", fmt.Sprintf(
+				`<stp-topology-change xmlns=%q><count>%d</count><port>%s</port></stp-topology-change>`,
+				NSGetStpState, tc.Count, server.EscapeXML(tc.LastPort)))
+		}
+		last = tc.Count
+		seeded = true
+	}
+}
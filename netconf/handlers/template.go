@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSTemplate is the namespace for template-based provisioning.
+const NSTemplate = "yang:template"
+
+// templates stores the defined provisioning templates by name. A
+// template body is a <config> payload in text/template syntax whose
+// variables ({{.vlan}}, {{.uplink}}) are bound by <apply>. When a
+// store path is configured the set persists there as JSON, so defined
+// templates survive gateway restarts.
+var templates struct {
+	sync.Mutex
+	byName map[string]string
+	path   string
+}
+
+// SetTemplateStore loads the persisted template set from path and
+// persists every later define and delete back to it.
+func SetTemplateStore(path string) error {
+	templates.Lock()
+	defer templates.Unlock()
+	templates.path = path
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &templates.byName); err != nil {
+		return fmt.Errorf("parse %s: %v", path, err)
+	}
+	return nil
+}
+
+// saveTemplatesLocked writes the template set to the configured store,
+// atomically via a rename; without a store templates stay in memory.
+func saveTemplatesLocked() error {
+	if templates.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(templates.byName, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := templates.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, templates.path)
+}
+
+func templateHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSTemplate,
+		RPC:       templateRPC,
+	}
+}
+
+// parseTemplate compiles one template body; missing variables fail the
+// expansion instead of rendering as "<no value>".
+func parseTemplate(name, body string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(body)
+}
+
+// templateRPC serves <define>, <apply>, <delete> and <list>. Apply
+// binds the given <var> values to the template variables and runs the
+// expansion through the normal edit path.
+func templateRPC(ctx *server.Context, op *server.Element) (string, error) {
+	switch op.Local() {
+	case "define":
+		name := op.ChildText("name")
+		if name == "" {
+			return "", server.MissingElement("name")
+		}
+		config := op.Child("config")
+		if config == nil || len(config.Children) == 0 {
+			return "", server.MissingElement("config")
+		}
+		body := server.RenderElement(config)
+		// Reject bodies that do not compile now rather than at apply
+		// time, when the operator who defined them is long gone.
+		if _, err := parseTemplate(name, body); err != nil {
+			return "", server.InvalidValue("template %q does not parse: %v", name, err)
+		}
+		templates.Lock()
+		defer templates.Unlock()
+		if templates.byName == nil {
+			templates.byName = make(map[string]string)
+		}
+		templates.byName[name] = body
+		if err := saveTemplatesLocked(); err != nil {
+			return "", server.OperationFailed("persist templates: %v", err)
+		}
+		return "<ok/>", nil
+	case "apply":
+		return templateApply(ctx, op)
+	case "delete":
+		name := op.ChildText("name")
+		templates.Lock()
+		defer templates.Unlock()
+		if _, ok := templates.byName[name]; !ok {
+			return "", server.InvalidValue("no template %q", name)
+		}
+		delete(templates.byName, name)
+		if err := saveTemplatesLocked(); err != nil {
+			return "", server.OperationFailed("persist templates: %v", err)
+		}
+		return "<ok/>", nil
+	case "list":
+		templates.Lock()
+		defer templates.Unlock()
+		var b strings.Builder
+		fmt.Fprintf(&b, `<templates xmlns=%q>`, NSTemplate)
+		for name := range templates.byName {
+			fmt.Fprintf(&b, "<template><name>%s</name></template>", server.EscapeXML(name))
+		}
+		b.WriteString("</templates>")
+		return b.String(), nil
+	default:
+		return "", server.OperationNotSupported("unknown template rpc <%s>", op.Local())
+	}
+}
+
+func templateApply(ctx *server.Context, op *server.Element) (string, error) {
+	name := op.ChildText("name")
+	templates.Lock()
+	body, ok := templates.byName[name]
+	templates.Unlock()
+	if !ok {
+		return "", server.InvalidValue("no template %q", name)
+	}
+	vars := make(map[string]string)
+	var varErr error
+	op.EachChild("var", func(v *server.Element) {
+		vn := v.Attr("name")
+		if vn == "" {
+			varErr = server.InvalidValue("template var needs a name attribute")
+			return
+		}
+		vars[vn] = strings.TrimSpace(v.Text)
+	})
+	if varErr != nil {
+		return "", varErr
+	}
+	tmpl, err := parseTemplate(name, body)
+	if err != nil {
+		return "", server.InvalidValue("template %q does not parse: %v", name, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return "", server.InvalidValue("template %q: %v", name, err)
+	}
+	config, err := server.ParseElement([]byte(out.String()))
+	if err != nil {
+		return "", server.OperationFailed("template %q expands to invalid XML: %v", name, err)
+	}
+	return applyTemplateConfig(ctx, name, config)
+}
+
+// applyTemplateConfig applies an expanded template under a device
+// snapshot when the daemon supports them, so a failure mid-apply rolls
+// the whole configuration back instead of leaving the template half
+// applied. Devices without snapshot support fall through to the plain
+// edit path, which stops at the first failure without rollback.
+func applyTemplateConfig(ctx *server.Context, name string, config *server.Element) (string, error) {
+	if !ctx.HasFeature(uidSnapshotSave) || !ctx.HasFeature(uidSnapshotRollback) {
+		if err := ctx.Server.ApplyConfig(ctx, config); err != nil {
+			return "", err
+		}
+		return "<ok/>", nil
+	}
+	snap := fmt.Sprintf("template-%s-%d", name, time.Now().Unix())
+	if _, err := ctx.Miyagi.Call(ctx, uidSnapshotSave, map[string]any{"name": snap}); err != nil {
+		return "", server.OperationFailed("snapshot before template apply: %v", err)
+	}
+	if err := ctx.Server.ApplyConfig(ctx, config); err != nil {
+		// Roll back on a background context: the rollback must run
+		// even when the client's deadline already expired.
+		bg := ctx.Server.BackgroundContext()
+		if _, rbErr := ctx.Miyagi.Call(bg, uidSnapshotRollback, map[string]any{"name": snap}); rbErr != nil {
+			return "", server.OperationFailed("template %q failed (%v) and rollback failed: %v", name, err, rbErr)
+		}
+		ctx.Miyagi.Call(bg, uidSnapshotDelete, map[string]any{"name": snap})
+		return "", server.OperationFailed("template %q failed and was rolled back: %v", name, err)
+	}
+	ctx.Miyagi.Call(ctx, uidSnapshotDelete, map[string]any{"name": snap})
+	return "<ok/>", nil
+}
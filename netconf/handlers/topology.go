@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSGetTopology is the namespace for the LLDP-derived topology view.
+const NSGetTopology = "yang:get_topology"
+
+const uidLldpNeighborsGet = "lldp.neighbors.get"
+
+func topologyHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSGetTopology,
+		Get:       getTopology,
+	}
+}
+
+// miyagiLldpNeighbor mirrors one entry of the lldp.neighbors.get
+// return payload.
+type miyagiLldpNeighbor struct {
+	LocalIfname  string `json:"local_ifname"`
+	RemoteSystem string `json:"remote_system"`
+	RemotePort   string `json:"remote_port"`
+	RemoteMgmtIP string `json:"remote_mgmt_ip"`
+}
+
+// getTopology correlates LLDP neighbors with the local port table and
+// returns one <link> per neighbor, so a controller can build the
+// network topology with a single RPC per device.
+func getTopology(ctx *server.Context, filter *server.Element) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidLldpNeighborsGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var neighbors []miyagiLldpNeighbor
+	if err := json.Unmarshal(ret, &neighbors); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidLldpNeighborsGet, err)
+	}
+	ret, err = ctx.Miyagi.Call(ctx, uidPortGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var ports []miyagiPort
+	if err := json.Unmarshal(ret, &ports); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidPortGet, err)
+	}
+	operStatus := make(map[string]string, len(ports))
+	for _, p := range ports {
+		operStatus[p.Name] = p.OperStatus
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<topology xmlns=%q>`, NSGetTopology)
+	for _, n := range neighbors {
+		b.WriteString("<link>")
+		fmt.Fprintf(&b, "<local-port>%s</local-port>", server.EscapeXML(n.LocalIfname))
+		if st := operStatus[n.LocalIfname]; st != "" {
+			fmt.Fprintf(&b, "<local-status>%s</local-status>", server.EscapeXML(st))
+		}
+		fmt.Fprintf(&b, "<remote-system>%s</remote-system><remote-port>%s</remote-port>",
+			server.EscapeXML(n.RemoteSystem), server.EscapeXML(n.RemotePort))
+		if n.RemoteMgmtIP != "" {
+			fmt.Fprintf(&b, "<remote-mgmt-ip>%s</remote-mgmt-ip>", server.EscapeXML(n.RemoteMgmtIP))
+		}
+		b.WriteString("</link>")
+	}
+	b.WriteString("</topology>")
+	return b.String(), nil
+}
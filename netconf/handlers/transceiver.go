@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// NSTransceiver is the namespace for transceiver digital optical
+// monitoring (DOM) and its alarm thresholds.
+const NSTransceiver = "yang:transceiver"
+
+const (
+	uidTransceiverDomGet = "transceiver.dom.get"
+	domPollInterval      = 30 * time.Second
+)
+
+func transceiverHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSTransceiver,
+		Get:       getTransceivers,
+		Edit:      editTransceiver,
+	}
+}
+
+// domThresholds holds the alarm levels for one port; nil pointers mean
+// "not monitored". Values are degrees C and dBm.
+type domThresholds struct {
+	TempHigh   *float64
+	RxPowerLow *float64
+	TxPowerLow *float64
+}
+
+// domState keeps the configured thresholds, per proxied device and
+// interface name so one device's levels never apply to another, and
+// which alarms are currently raised. The alarm poller only watches the
+// default device (device key "").
+var domState struct {
+	sync.Mutex
+	thresholds map[string]map[string]*domThresholds // device -> ifname
+	raised     map[string]bool                      // "ifname/metric" -> alarm active
+}
+
+// miyagiDomEntry mirrors one entry of the transceiver.dom.get return
+// payload.
+type miyagiDomEntry struct {
+	Ifname  string  `json:"ifname"`
+	TempC   float64 `json:"temp_c"`
+	RxPower float64 `json:"rx_power_dbm"`
+	TxPower float64 `json:"tx_power_dbm"`
+	Bias    float64 `json:"bias_ma"`
+}
+
+// editTransceiver stores alarm thresholds for one port. Thresholds
+// live in the gateway; the poller compares them against DOM readings.
+func editTransceiver(ctx *server.Context, item *server.Element) error {
+	name, err := InterfaceRef(ctx, item)
+	if err != nil {
+		return err
+	}
+	th := item.Child("thresholds")
+	if th == nil {
+		return server.MissingElement("thresholds")
+	}
+	parsed := &domThresholds{}
+	for tag, dst := range map[string]**float64{
+		"temperature-high": &parsed.TempHigh,
+		"rx-power-low":     &parsed.RxPowerLow,
+		"tx-power-low":     &parsed.TxPowerLow,
+	} {
+		if txt := th.ChildText(tag); txt != "" {
+			v, err := strconv.ParseFloat(txt, 64)
+			if err != nil {
+				return server.InvalidValue("<%s> must be numeric, got %q", tag, txt)
+			}
+			*dst = &v
+		}
+	}
+	domState.Lock()
+	defer domState.Unlock()
+	if domState.thresholds == nil {
+		domState.thresholds = make(map[string]map[string]*domThresholds)
+	}
+	if domState.thresholds[ctx.Device] == nil {
+		domState.thresholds[ctx.Device] = make(map[string]*domThresholds)
+	}
+	domState.thresholds[ctx.Device][name] = parsed
+	return nil
+}
+
+// getTransceivers returns the live DOM readings plus any configured
+// thresholds per port.
+func getTransceivers(ctx *server.Context, filter *server.Element) (string, error) {
+	entries, err := fetchDom(ctx)
+	if err != nil {
+		return "", err
+	}
+	domState.Lock()
+	defer domState.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<transceivers xmlns=%q>`, NSTransceiver)
+	for _, e := range entries {
+		b.WriteString("<transceiver>")
+		fmt.Fprintf(&b, "<name>%s</name>", server.EscapeXML(e.Ifname))
+		fmt.Fprintf(&b, "<temperature>%.1f</temperature><rx-power>%.2f</rx-power><tx-power>%.2f</tx-power><bias-current>%.2f</bias-current>",
+			e.TempC, e.RxPower, e.TxPower, e.Bias)
+		if th := domState.thresholds[ctx.Device][e.Ifname]; th != nil {
+			b.WriteString("<thresholds>")
+			if th.TempHigh != nil {
+				fmt.Fprintf(&b, "<temperature-high>%.1f</temperature-high>", *th.TempHigh)
+			}
+			if th.RxPowerLow != nil {
+				fmt.Fprintf(&b, "<rx-power-low>%.2f</rx-power-low>", *th.RxPowerLow)
+			}
+			if th.TxPowerLow != nil {
+				fmt.Fprintf(&b, "<tx-power-low>%.2f</tx-power-low>", *th.TxPowerLow)
+			}
+			b.WriteString("</thresholds>")
+		}
+		b.WriteString("</transceiver>")
+	}
+	b.WriteString("</transceivers>")
+	return b.String(), nil
+}
+
+func fetchDom(ctx *server.Context) ([]miyagiDomEntry, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidTransceiverDomGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	var entries []miyagiDomEntry
+	if err := json.Unmarshal(ret, &entries); err != nil {
+		return nil, server.OperationFailed("decode %s reply: %v", uidTransceiverDomGet, err)
+	}
+	return entries, nil
+}
+
+// WatchTransceiverAlarms polls DOM readings and emits an
+// <optical-alarm> notification on the ENVIRONMENT stream whenever a
+// reading crosses a configured threshold, and again when it clears.
+func WatchTransceiverAlarms(srv *server.Server) {
+	ctx := srv.BackgroundContext()
+	if !ctx.HasFeature(uidTransceiverDomGet) {
+		return
+	}
+	ticker := time.NewTicker(domPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		entries, err := fetchDom(ctx)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			domState.Lock()
+			th := domState.thresholds[""][e.Ifname]
+			domState.Unlock()
+			if th == nil {
+				continue
+			}
+			if th.TempHigh != nil {
+				checkAlarm(srv, e.Ifname, "temperature", e.TempC, *th.TempHigh, e.TempC > *th.TempHigh)
+			}
+			if th.RxPowerLow != nil {
+				checkAlarm(srv, e.Ifname, "rx-power", e.RxPower, *th.RxPowerLow, e.RxPower < *th.RxPowerLow)
+			}
+			if th.TxPowerLow != nil {
+				checkAlarm(srv, e.Ifname, "tx-power", e.TxPower, *th.TxPowerLow, e.TxPower < *th.TxPowerLow)
+			}
+		}
+	}
+}
+
+// checkAlarm tracks per-metric alarm state and notifies only on
+// transitions, so a reading sitting past its threshold does not flood
+// subscribers every poll.
+func checkAlarm(srv *server.Server, ifname, metric string, value, threshold float64, active bool) {
+	key := ifname + "/" + metric
+	domState.Lock()
+	was := domState.raised[key]
+	if domState.raised == nil {
+		domState.raised = make(map[string]bool)
+	}
+	domState.raised[key] = active
+	domState.Unlock()
+	if was == active {
+		return
+	}
+	event := "raised"
+	if !active {
+		event = "cleared"
+	}
+	srv.Notify("ENVIRONMENT", fmt.Sprintf(
+		`<optical-alarm xmlns=%q><port>%s</port><metric>%s</metric><value>%.2f</value><threshold>%.2f</threshold><state>%s</state></optical-alarm>`,
+		NSTransceiver, server.EscapeXML(ifname), metric, value, threshold, event))
+}
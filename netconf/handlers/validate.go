@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// Shared address validators. Handlers that take IP address and netmask
+// leaves (ip-interface, oob, routes) run values through these before
+// any Miyagi call, so a typo fails with invalid-value and the leaf's
+// path instead of an opaque device rejection.
+
+// checkAddress validates one IP address leaf.
+func checkAddress(path, value string) error {
+	if net.ParseIP(value) == nil {
+		return invalidLeaf(path, "%q is not a valid IP address", value)
+	}
+	return nil
+}
+
+// checkMask validates a dotted-quad IPv4 netmask, requiring the set
+// bits to be contiguous.
+func checkMask(path, value string) error {
+	v4 := net.ParseIP(value).To4()
+	if v4 == nil {
+		return invalidLeaf(path, "%q is not a valid IPv4 netmask", value)
+	}
+	if m := binary.BigEndian.Uint32(v4); m != 0 && (^m+1)&^m != 0 {
+		return invalidLeaf(path, "netmask %q does not have contiguous bits", value)
+	}
+	return nil
+}
+
+// invalidLeaf builds an invalid-value error carrying the offending
+// element path, so clients can locate the leaf without parsing the
+// message text.
+func invalidLeaf(path, format string, args ...any) error {
+	err := server.InvalidValue(format, args...)
+	err.Path = path
+	return err
+}
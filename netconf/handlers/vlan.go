@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// Namespaces for VLAN configuration and the enriched membership view.
+const (
+	NSVlan           = "yang:vlan"
+	NSGetVlanMembers = "yang:get_vlan_members"
+)
+
+const (
+	uidVlanCreate        = "vlan.create"
+	uidVlanDelete        = "vlan.delete"
+	uidVlanGet           = "vlan.get"
+	uidVlanFloodSet      = "vlan.flood_control.set"
+	uidVlanFloodGet      = "vlan.flood_control.get"
+	uidIntfSwitchportGet = "interface.switchport.get"
+)
+
+// miyagiVlan mirrors one entry of the vlan.get return payload.
+type miyagiVlan struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// miyagiSwitchport mirrors one entry of the interface.switchport.get
+// return payload.
+type miyagiSwitchport struct {
+	Name         string `json:"ifname"`
+	Mode         string `json:"mode"` // "access", "trunk" or "tunnel"
+	AccessVlan   int    `json:"access_vlan"`
+	NativeVlan   int    `json:"native_vlan"`
+	AllowedVlans []int  `json:"allowed_vlans"`
+	TPID         string `json:"tpid"`  // e.g. "0x88a8", QinQ ports only
+	SVlan        int    `json:"svlan"` // service VLAN, QinQ ports only
+}
+
+func vlanHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSVlan,
+		// Older controllers address VLAN config through the short
+		// naming scheme.
+		Aliases: []string{"yang:set_vlan"},
+		Get:     getVlans,
+		Edit:    editVlan,
+	}
+}
+
+func vlanMembersHandler() *server.Handler {
+	return &server.Handler{
+		Namespace: NSGetVlanMembers,
+		Get:       getVlanMembers,
+	}
+}
+
+func fetchVlans(ctx *server.Context) ([]miyagiVlan, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidVlanGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	var vlans []miyagiVlan
+	if err := json.Unmarshal(ret, &vlans); err != nil {
+		return nil, server.OperationFailed("decode %s reply: %v", uidVlanGet, err)
+	}
+	return vlans, nil
+}
+
+// BuildGetVlansResponse renders the plain VLAN list (id and name only).
+func BuildGetVlansResponse(vlans []miyagiVlan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<vlans xmlns=%q>`, NSVlan)
+	for _, v := range vlans {
+		fmt.Fprintf(&b, "<vlan><id>%d</id><name>%s</name></vlan>", v.ID, server.EscapeXML(v.Name))
+	}
+	b.WriteString("</vlans>")
+	return b.String()
+}
+
+func getVlans(ctx *server.Context, filter *server.Element) (string, error) {
+	vlans, err := fetchVlans(ctx)
+	if err != nil {
+		return "", err
+	}
+	out := BuildGetVlansResponse(vlans)
+	if ctx.HasFeature(uidVlanFloodGet) {
+		flood, err := buildFloodControlResponse(ctx)
+		if err != nil {
+			return "", err
+		}
+		out += flood
+	}
+	return out, nil
+}
+
+// buildFloodControlResponse renders the per-VLAN flood-control state as
+// a sibling element of the VLAN list.
+func buildFloodControlResponse(ctx *server.Context) (string, error) {
+	ret, err := ctx.Miyagi.Call(ctx, uidVlanFloodGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var entries []struct {
+		Vlan             int  `json:"vlan"`
+		UnknownUnicast   bool `json:"unknown_unicast_block"`
+		UnknownMulticast bool `json:"unknown_multicast_block"`
+	}
+	if err := json.Unmarshal(ret, &entries); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidVlanFloodGet, err)
+	}
+	mode := func(block bool) string {
+		if block {
+			return "block"
+		}
+		return "flood"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<flood-controls xmlns=%q>`, NSVlan)
+	for _, e := range entries {
+		fmt.Fprintf(&b, "<flood-control><vlan>%d</vlan><unknown-unicast>%s</unknown-unicast><unknown-multicast>%s</unknown-multicast></flood-control>",
+			e.Vlan, mode(e.UnknownUnicast), mode(e.UnknownMulticast))
+	}
+	b.WriteString("</flood-controls>")
+	return b.String(), nil
+}
+
+// getVlanMembers builds the enriched per-VLAN view: for every VLAN the
+// tagged and untagged member ports, computed by joining the VLAN table
+// with the per-port switchport table.
+func getVlanMembers(ctx *server.Context, filter *server.Element) (string, error) {
+	vlans, err := fetchVlans(ctx)
+	if err != nil {
+		return "", err
+	}
+	ret, err := ctx.Miyagi.Call(ctx, uidIntfSwitchportGet, nil)
+	if err != nil {
+		return "", err
+	}
+	var ports []miyagiSwitchport
+	if err := json.Unmarshal(ret, &ports); err != nil {
+		return "", server.OperationFailed("decode %s reply: %v", uidIntfSwitchportGet, err)
+	}
+
+	tagged := make(map[int][]string)
+	untagged := make(map[int][]string)
+	for _, p := range ports {
+		switch p.Mode {
+		case "access":
+			untagged[p.AccessVlan] = append(untagged[p.AccessVlan], p.Name)
+		case "trunk":
+			untagged[p.NativeVlan] = append(untagged[p.NativeVlan], p.Name)
+			for _, id := range p.AllowedVlans {
+				if id != p.NativeVlan {
+					tagged[id] = append(tagged[id], p.Name)
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<vlans xmlns=%q>`, NSGetVlanMembers)
+	for _, v := range vlans {
+		fmt.Fprintf(&b, "<vlan><id>%d</id><name>%s</name>", v.ID, server.EscapeXML(v.Name))
+		writeMemberPorts(&b, "untagged", untagged[v.ID])
+		writeMemberPorts(&b, "tagged", tagged[v.ID])
+		b.WriteString("</vlan>")
+	}
+	b.WriteString("</vlans>")
+	return b.String(), nil
+}
+
+func writeMemberPorts(b *strings.Builder, kind string, ports []string) {
+	fmt.Fprintf(b, "<%s>", kind)
+	for _, p := range ports {
+		fmt.Fprintf(b, "<port>%s</port>", server.EscapeXML(p))
+	}
+	fmt.Fprintf(b, "</%s>", kind)
+}
+
+// editVlan creates or deletes one VLAN.
+func editVlan(ctx *server.Context, item *server.Element) error {
+	if item.Local() == "flood-control" {
+		return editVlanFloodControl(ctx, item)
+	}
+	if item.Local() == "vlan-range" {
+		return editVlanRange(ctx, item)
+	}
+	id, err := parseVlanID(item.ChildText("id"))
+	if err != nil {
+		return err
+	}
+	if item.Attr("operation") == "delete" || item.ChildText("operation") == "delete" {
+		_, err := ctx.Miyagi.Call(ctx, uidVlanDelete, map[string]any{"id": id})
+		return err
+	}
+	arg := map[string]any{"id": id}
+	if name := item.ChildText("name"); name != "" {
+		arg["name"] = name
+	}
+	_, err = ctx.Miyagi.Call(ctx, uidVlanCreate, arg)
+	return err
+}
+
+// vlanBatchWorkers bounds the Miyagi calls a range expansion runs in
+// parallel, so a 4000-VLAN range cannot swamp the daemon.
+const vlanBatchWorkers = 8
+
+// editVlanRange creates (or, with operation="delete", deletes) every
+// VLAN in a list of ranges given as the element text, e.g.
+// <vlan-range name-template="VLAN%d">100-150</vlan-range>. The
+// optional name template must contain a single %d, which is replaced
+// with the VLAN id. Expansion runs server-side on a bounded worker
+// pool; the first failure is reported but does not undo ids already
+// applied.
+func editVlanRange(ctx *server.Context, item *server.Element) error {
+	ids, err := parseVlanList(strings.TrimSpace(item.Text))
+	if err != nil {
+		return err
+	}
+	template := item.Attr("name-template")
+	if template != "" && (strings.Count(template, "%") != 1 || !strings.Contains(template, "%d")) {
+		return server.InvalidValue("name-template must contain exactly one %%d, got %q", template)
+	}
+	uid := uidVlanCreate
+	if item.Attr("operation") == "delete" || item.ChildText("operation") == "delete" {
+		uid = uidVlanDelete
+	}
+
+	work := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < vlanBatchWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range work {
+				arg := map[string]any{"id": id}
+				if uid == uidVlanCreate && template != "" {
+					arg["name"] = fmt.Sprintf(template, id)
+				}
+				if _, err := ctx.Miyagi.Call(ctx, uid, arg); err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, id := range ids {
+		work <- id
+	}
+	close(work)
+	wg.Wait()
+	return firstErr
+}
+
+// editVlanFloodControl sets per-VLAN flooding behavior for unknown
+// unicast and unknown multicast: "block" drops the traffic, "flood"
+// restores the default.
+func editVlanFloodControl(ctx *server.Context, item *server.Element) error {
+	id, err := parseVlanID(item.ChildText("vlan"))
+	if err != nil {
+		return err
+	}
+	arg := map[string]any{"vlan": id}
+	applied := false
+	for tag, key := range map[string]string{
+		"unknown-unicast":   "unknown_unicast_block",
+		"unknown-multicast": "unknown_multicast_block",
+	} {
+		v := item.ChildText(tag)
+		if v == "" {
+			continue
+		}
+		if v != "block" && v != "flood" {
+			return server.InvalidValue("vlan %d: <%s> must be block or flood, got %q", id, tag, v)
+		}
+		arg[key] = v == "block"
+		applied = true
+	}
+	if !applied {
+		return server.MissingElement("unknown-unicast or unknown-multicast")
+	}
+	_, err = ctx.Miyagi.Call(ctx, uidVlanFloodSet, arg)
+	return err
+}
+
+// parseVlanList parses a comma-separated list of VLAN ids and
+// ascending ranges, e.g. "10,20-30,99".
+func parseVlanList(s string) ([]int, error) {
+	var ids []int
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(entry, "-")
+		from, err := parseVlanID(lo)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = parseVlanID(hi); err != nil {
+				return nil, err
+			}
+			if to < from {
+				return nil, server.InvalidValue("vlan range %q must be ascending", entry)
+			}
+		}
+		for id := from; id <= to; id++ {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, server.InvalidValue("empty vlan list")
+	}
+	return ids, nil
+}
+
+// ensureVlansExist verifies every id against the device's VLAN table.
+// Missing VLANs are created when the server runs with auto-create,
+// otherwise the first offender is reported as data-missing with its id
+// in error-info. Devices without vlan.get cannot be checked.
+func ensureVlansExist(ctx *server.Context, ids []int) error {
+	if !ctx.HasFeature(uidVlanGet) {
+		return nil
+	}
+	vlans, err := fetchVlans(ctx)
+	if err != nil {
+		return err
+	}
+	exists := make(map[int]bool, len(vlans))
+	for _, v := range vlans {
+		exists[v.ID] = true
+	}
+	for _, id := range ids {
+		if exists[id] {
+			continue
+		}
+		if !ctx.Server.AutoCreateVlans {
+			rpcErr := server.DataMissing("vlan %d does not exist", id)
+			rpcErr.Info = fmt.Sprintf("<vlan-id>%d</vlan-id>", id)
+			return rpcErr
+		}
+		if _, err := ctx.Miyagi.Call(ctx, uidVlanCreate, map[string]any{"id": id}); err != nil {
+			return err
+		}
+		exists[id] = true
+	}
+	return nil
+}
+
+func parseVlanID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 || id > 4094 {
+		return 0, server.InvalidValue("vlan id must be 1..4094, got %q", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,73 @@
+package miyagi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// uidCapabilitiesGet lists the UIDs the daemon implements. Old daemons
+// do not implement it themselves.
+const uidCapabilitiesGet = "system.capabilities.get"
+
+// FeatureSet records which Miyagi UIDs the connected daemon supports.
+// A nil FeatureSet means probing was unavailable and every UID is
+// assumed to be supported.
+type FeatureSet struct {
+	uids map[string]bool
+}
+
+// Has reports whether uid is supported.
+func (f *FeatureSet) Has(uid string) bool {
+	if f == nil {
+		return true
+	}
+	return f.uids[uid]
+}
+
+// uidSystemInfoGet returns static device identity (model, serial,
+// software version).
+const uidSystemInfoGet = "system.info.get"
+
+// SystemInfo is the device identity reported by the daemon.
+type SystemInfo struct {
+	Model     string `json:"model"`
+	Serial    string `json:"serial"`
+	SWVersion string `json:"sw_version"`
+}
+
+// ProbeSystemInfo asks the daemon who it is. Daemons without the UID
+// yield a zero SystemInfo rather than an error, mirroring Probe.
+func ProbeSystemInfo(ctx context.Context, c Caller) (SystemInfo, error) {
+	var info SystemInfo
+	ret, err := c.Call(ctx, uidSystemInfoGet, nil)
+	if err != nil {
+		if _, ok := err.(*Error); ok {
+			return info, nil
+		}
+		return info, err
+	}
+	err = json.Unmarshal(ret, &info)
+	return info, err
+}
+
+// Probe asks the daemon for its supported UIDs. Daemons that do not
+// implement the probe UID yield a nil FeatureSet (assume everything).
+func Probe(ctx context.Context, c Caller) (*FeatureSet, error) {
+	ret, err := c.Call(ctx, uidCapabilitiesGet, nil)
+	if err != nil {
+		if _, ok := err.(*Error); ok {
+			// The daemon answered but does not know the UID.
+			return nil, nil
+		}
+		return nil, err
+	}
+	var uids []string
+	if err := json.Unmarshal(ret, &uids); err != nil {
+		return nil, err
+	}
+	f := &FeatureSet{uids: make(map[string]bool, len(uids))}
+	for _, uid := range uids {
+		f.uids[uid] = true
+	}
+	return f, nil
+}
@@ -0,0 +1,97 @@
+package miyagi
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UIDMetric is a snapshot of the counters for one UID.
+type UIDMetric struct {
+	UID       string  `json:"uid"`
+	Calls     uint64  `json:"calls"`
+	Errors    uint64  `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+	AvgMillis float64 `json:"avg_ms"`
+	MaxMillis float64 `json:"max_ms"`
+}
+
+type uidStats struct {
+	calls  uint64
+	errors uint64
+	total  time.Duration
+	max    time.Duration
+}
+
+// Metrics wraps a Caller and keeps per-UID latency and error counters.
+type Metrics struct {
+	next Caller
+
+	mu      sync.Mutex
+	stats   map[string]*uidStats
+	lastAt  time.Time
+	lastErr error
+}
+
+// NewMetrics wraps next with per-UID metric collection.
+func NewMetrics(next Caller) *Metrics {
+	return &Metrics{next: next, stats: make(map[string]*uidStats)}
+}
+
+// Call forwards to the wrapped Caller, timing the exchange.
+func (m *Metrics) Call(ctx context.Context, uid string, arg any) (json.RawMessage, error) {
+	start := time.Now()
+	ret, err := m.next.Call(ctx, uid, arg)
+	elapsed := time.Since(start)
+
+	m.mu.Lock()
+	st := m.stats[uid]
+	if st == nil {
+		st = &uidStats{}
+		m.stats[uid] = st
+	}
+	st.calls++
+	if err != nil {
+		st.errors++
+	}
+	st.total += elapsed
+	if elapsed > st.max {
+		st.max = elapsed
+	}
+	m.lastAt = start
+	m.lastErr = err
+	m.mu.Unlock()
+	return ret, err
+}
+
+// Health reports when the daemon was last called and how that call
+// went; a zero time means no call has been made yet.
+func (m *Metrics) Health() (lastAt time.Time, lastErr error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastAt, m.lastErr
+}
+
+// Snapshot returns the per-UID counters sorted by UID.
+func (m *Metrics) Snapshot() []UIDMetric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]UIDMetric, 0, len(m.stats))
+	for uid, st := range m.stats {
+		um := UIDMetric{
+			UID:       uid,
+			Calls:     st.calls,
+			Errors:    st.errors,
+			MaxMillis: float64(st.max) / float64(time.Millisecond),
+		}
+		if st.calls > 0 {
+			um.ErrorRate = float64(st.errors) / float64(st.calls)
+			um.AvgMillis = float64(st.total) / float64(st.calls) / float64(time.Millisecond)
+		}
+		out = append(out, um)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UID < out[j].UID })
+	return out
+}
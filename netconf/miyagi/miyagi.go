@@ -0,0 +1,139 @@
+// Package miyagi implements the JSON-over-unix-socket client used to talk
+// to the Miyagi API daemon running on the switch. Every call names a UID
+// (a dotted method identifier such as "vlan.create") and carries a JSON
+// argument object; the daemon answers with a status code and a return
+// payload.
+package miyagi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Caller is the interface the NETCONF handlers use to reach the Miyagi
+// daemon. It is satisfied by *Client and by test doubles.
+type Caller interface {
+	Call(ctx context.Context, uid string, arg any) (json.RawMessage, error)
+}
+
+type request struct {
+	UID string `json:"uid"`
+	Arg any    `json:"arg,omitempty"`
+}
+
+type response struct {
+	Status int             `json:"status"`
+	Error  string          `json:"error,omitempty"`
+	Ret    json.RawMessage `json:"ret,omitempty"`
+}
+
+// Error is returned when the Miyagi daemon rejects a call.
+type Error struct {
+	UID    string
+	Status int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("miyagi: %s failed with status %d: %s", e.UID, e.Status, e.Msg)
+}
+
+// Client is a synchronous Miyagi client. Calls are serialized on the
+// single daemon connection.
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+	path string
+}
+
+// Dial connects to the Miyagi daemon listening on the given unix socket.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("miyagi: dial %s: %w", path, err)
+	}
+	return &Client{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(conn),
+		path: path,
+	}, nil
+}
+
+// Call invokes uid with the given argument and returns the raw return
+// payload. A non-zero daemon status is surfaced as *Error. After a
+// transport failure the connection is dropped and the next call dials
+// a fresh one: a deadline that fires mid-call leaves the reply in
+// flight, and decoding it as the next call's response would pair that
+// call with the wrong result.
+func (c *Client) Call(ctx context.Context, uid string, arg any) (json.RawMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		if err := c.redialLocked(); err != nil {
+			return nil, err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetDeadline(deadline)
+		defer func() {
+			if c.conn != nil {
+				c.conn.SetDeadline(time.Time{})
+			}
+		}()
+	}
+	if err := c.enc.Encode(request{UID: uid, Arg: arg}); err != nil {
+		c.resetLocked()
+		return nil, fmt.Errorf("miyagi: send %s: %w", uid, err)
+	}
+	var resp response
+	if err := c.dec.Decode(&resp); err != nil {
+		c.resetLocked()
+		return nil, fmt.Errorf("miyagi: recv %s: %w", uid, err)
+	}
+	if resp.Status != 0 {
+		return nil, &Error{UID: uid, Status: resp.Status, Msg: resp.Error}
+	}
+	return resp.Ret, nil
+}
+
+// resetLocked discards the connection after a send or receive failure.
+// The stream position is indeterminate at that point (a stale reply may
+// still arrive, or half a reply was consumed), so the connection must
+// never be reused.
+func (c *Client) resetLocked() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn, c.enc, c.dec = nil, nil, nil
+	}
+}
+
+// redialLocked re-establishes the daemon connection.
+func (c *Client) redialLocked() error {
+	conn, err := net.Dial("unix", c.path)
+	if err != nil {
+		return fmt.Errorf("miyagi: dial %s: %w", c.path, err)
+	}
+	c.conn = conn
+	c.enc = json.NewEncoder(conn)
+	c.dec = json.NewDecoder(conn)
+	return nil
+}
+
+// Close tears down the daemon connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.enc, c.dec = nil, nil, nil
+	return err
+}
@@ -0,0 +1,105 @@
+package miyagi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Record is one recorded Miyagi exchange, stored as a JSON line.
+type Record struct {
+	Time time.Time       `json:"time"`
+	UID  string          `json:"uid"`
+	Arg  json.RawMessage `json:"arg,omitempty"`
+	Ret  json.RawMessage `json:"ret,omitempty"`
+	Err  string          `json:"err,omitempty"`
+}
+
+// Recorder wraps a Caller and appends every exchange to a JSONL file,
+// so that bug reports can ship the exact device traffic.
+type Recorder struct {
+	next Caller
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// NewRecorder records all traffic through next into path (appending).
+func NewRecorder(next Caller, path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("miyagi: open record file: %w", err)
+	}
+	return &Recorder{next: next, f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Call forwards to the wrapped Caller and records the exchange.
+func (r *Recorder) Call(ctx context.Context, uid string, arg any) (json.RawMessage, error) {
+	ret, err := r.next.Call(ctx, uid, arg)
+	rec := Record{Time: time.Now(), UID: uid, Ret: ret}
+	if arg != nil {
+		rec.Arg, _ = json.Marshal(arg)
+	}
+	if err != nil {
+		rec.Err = err.Error()
+	}
+	r.mu.Lock()
+	r.enc.Encode(rec)
+	r.mu.Unlock()
+	return ret, err
+}
+
+// Close flushes and closes the record file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// Replayer serves Miyagi calls from a recorded file instead of a live
+// daemon, matching records by UID in file order. It is used to
+// reproduce reported bugs without the device.
+type Replayer struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewReplayer loads a JSONL file written by a Recorder.
+func NewReplayer(path string) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("miyagi: open replay file: %w", err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	var records []Record
+	for dec.More() {
+		var rec Record
+		if err := dec.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("miyagi: parse replay file: %w", err)
+		}
+		records = append(records, rec)
+	}
+	return &Replayer{records: records}, nil
+}
+
+// Call consumes the first unused record with a matching UID.
+func (r *Replayer) Call(ctx context.Context, uid string, arg any) (json.RawMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, rec := range r.records {
+		if rec.UID != uid {
+			continue
+		}
+		r.records = append(r.records[:i], r.records[i+1:]...)
+		if rec.Err != "" {
+			return nil, &Error{UID: uid, Status: -1, Msg: rec.Err}
+		}
+		return rec.Ret, nil
+	}
+	return nil, &Error{UID: uid, Status: -1, Msg: "no recorded response"}
+}
@@ -0,0 +1,74 @@
+// Package netconfutil holds the protocol-level helpers shared by the
+// NETCONF server and any tool that needs to speak or parse the wire
+// format (reply envelopes, rpc-error rendering, escaping, framing)
+// without pulling in the whole server.
+package netconfutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// BaseNS is the NETCONF base-1.0 namespace.
+const BaseNS = "urn:ietf:params:xml:ns:netconf:base:1.0"
+
+// EOMMarker terminates NETCONF 1.0 frames.
+const EOMMarker = "]]>]]>"
+
+// XMLDeclaration is the standard declaration prepended to frames in
+// strict mode.
+const XMLDeclaration = `<?xml version="1.0" encoding="UTF-8"?>`
+
+// EscapeXML escapes a string for inclusion in element content.
+func EscapeXML(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// RPCReply wraps inner XML in an <rpc-reply> element for the given
+// message-id.
+func RPCReply(msgID, inner string) string {
+	return fmt.Sprintf(`<rpc-reply message-id=%q xmlns=%q>%s</rpc-reply>`, msgID, BaseNS, inner)
+}
+
+// OKReply builds the <ok/> reply used for successful edits.
+func OKReply(msgID string) string {
+	return RPCReply(msgID, "<ok/>")
+}
+
+// DataReply wraps inner XML in <rpc-reply><data>...</data></rpc-reply>.
+func DataReply(msgID, inner string) string {
+	return RPCReply(msgID, "<data>"+inner+"</data>")
+}
+
+// RenderRPCError renders one <rpc-error> element. An empty severity
+// defaults to "error"; path, when non-empty, names the offending
+// element as <error-path>; info, when non-empty, is inner XML emitted
+// verbatim as <error-info>.
+func RenderRPCError(errType, tag, severity, message, path, info string) string {
+	if severity == "" {
+		severity = "error"
+	}
+	var b strings.Builder
+	b.WriteString("<rpc-error>")
+	fmt.Fprintf(&b, "<error-type>%s</error-type>", errType)
+	fmt.Fprintf(&b, "<error-tag>%s</error-tag>", tag)
+	fmt.Fprintf(&b, "<error-severity>%s</error-severity>", severity)
+	if path != "" {
+		fmt.Fprintf(&b, "<error-path>%s</error-path>", EscapeXML(path))
+	}
+	fmt.Fprintf(&b, "<error-message>%s</error-message>", EscapeXML(message))
+	if info != "" {
+		fmt.Fprintf(&b, "<error-info>%s</error-info>", info)
+	}
+	b.WriteString("</rpc-error>")
+	return b.String()
+}
+
+// Frame appends the end-of-message marker to one outbound frame.
+func Frame(s string) string {
+	return s + "\n" + EOMMarker + "\n"
+}
@@ -0,0 +1,162 @@
+// Package notify contains external publishers for the server's event
+// bus, so switch notifications can feed message brokers in addition to
+// NETCONF subscribers. Publishers implement server.NotificationSink;
+// MQTT is built in and a Kafka producer can plug into the same sink
+// interface.
+package notify
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/server"
+)
+
+// MQTTPublisher publishes every notification to an MQTT 3.1.1 broker
+// with QoS 0, on topic <prefix>/<stream>. It speaks the protocol
+// directly so the device needs no client library.
+type MQTTPublisher struct {
+	addr     string
+	clientID string
+	prefix   string
+
+	mu   sync.Mutex
+	conn net.Conn
+
+	queue chan server.Notification
+}
+
+// NewMQTTPublisher connects to the broker at addr (host:port) and
+// publishes under the given topic prefix.
+func NewMQTTPublisher(addr, clientID, prefix string) *MQTTPublisher {
+	p := &MQTTPublisher{
+		addr:     addr,
+		clientID: clientID,
+		prefix:   prefix,
+		queue:    make(chan server.Notification, 256),
+	}
+	go p.run()
+	return p
+}
+
+// Publish enqueues a notification; events are dropped when the queue is
+// full so a dead broker cannot stall the server.
+func (p *MQTTPublisher) Publish(n server.Notification) {
+	select {
+	case p.queue <- n:
+	default:
+	}
+}
+
+func (p *MQTTPublisher) run() {
+	for n := range p.queue {
+		if err := p.publish(n); err != nil {
+			p.mu.Lock()
+			if p.conn != nil {
+				p.conn.Close()
+				p.conn = nil
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *MQTTPublisher) publish(n server.Notification) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		if err := p.connectLocked(); err != nil {
+			return err
+		}
+	}
+	topic := p.prefix + "/" + n.Stream
+	payload := server.NotificationElement(n)
+	pkt := publishPacket(topic, []byte(payload))
+	p.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	_, err := p.conn.Write(pkt)
+	return err
+}
+
+func (p *MQTTPublisher) connectLocked() error {
+	conn, err := net.DialTimeout("tcp", p.addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write(connectPacket(p.clientID)); err != nil {
+		conn.Close()
+		return err
+	}
+	// CONNACK: fixed header 0x20 0x02, then flags and return code.
+	ack := make([]byte, 4)
+	if _, err := readFull(conn, ack); err != nil {
+		conn.Close()
+		return err
+	}
+	if ack[0] != 0x20 || ack[3] != 0 {
+		conn.Close()
+		return fmt.Errorf("notify: mqtt connect refused (code %d)", ack[3])
+	}
+	conn.SetDeadline(time.Time{})
+	p.conn = conn
+	return nil
+}
+
+func readFull(conn net.Conn, buf []byte) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := conn.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// connectPacket builds an MQTT CONNECT packet with a clean session.
+func connectPacket(clientID string) []byte {
+	var body []byte
+	body = appendMQTTString(body, "MQTT")
+	body = append(body, 4)    // protocol level 3.1.1
+	body = append(body, 0x02) // clean session
+	body = append(body, 0, 60)
+	body = appendMQTTString(body, clientID)
+	return appendFixedHeader(0x10, body)
+}
+
+// publishPacket builds a QoS 0 PUBLISH packet.
+func publishPacket(topic string, payload []byte) []byte {
+	var body []byte
+	body = appendMQTTString(body, topic)
+	body = append(body, payload...)
+	return appendFixedHeader(0x30, body)
+}
+
+func appendMQTTString(b []byte, s string) []byte {
+	var l [2]byte
+	binary.BigEndian.PutUint16(l[:], uint16(len(s)))
+	return append(append(b, l[:]...), s...)
+}
+
+// appendFixedHeader prepends the packet type and the variable-length
+// remaining-length encoding.
+func appendFixedHeader(packetType byte, body []byte) []byte {
+	out := []byte{packetType}
+	n := len(body)
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		out = append(out, digit)
+		if n == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// AccessList is a source-IP filter evaluated when a connection is
+// accepted, before any NETCONF bytes are exchanged, so the management
+// port can be restricted to management subnets without an external
+// firewall. Deny entries win over allow entries; a non-empty allow
+// list admits only matching sources. The zero/nil AccessList permits
+// everything.
+type AccessList struct {
+	mu    sync.Mutex
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// ParseAccessList builds an AccessList from comma-separated CIDR
+// lists; bare addresses are treated as host routes.
+func ParseAccessList(allow, deny string) (*AccessList, error) {
+	a := &AccessList{}
+	return a, a.Reload(allow, deny)
+}
+
+// Reload atomically replaces both lists, so the filter can be updated
+// on a running server without dropping established sessions.
+func (a *AccessList) Reload(allow, deny string) error {
+	allowNets, err := parseCIDRList(allow)
+	if err != nil {
+		return err
+	}
+	denyNets, err := parseCIDRList(deny)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.allow, a.deny = allowNets, denyNets
+	a.mu.Unlock()
+	return nil
+}
+
+func parseCIDRList(s string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil {
+				bits := 32
+				if ip.To4() == nil {
+					bits = 128
+				}
+				entry = fmt.Sprintf("%s/%d", entry, bits)
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("acl: bad entry %q: %v", entry, err)
+		}
+		nets = append(nets, ipnet)
+	}
+	return nets, nil
+}
+
+// Permit reports whether a connection from addr may proceed.
+func (a *AccessList) Permit(addr net.Addr) bool {
+	if a == nil {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		// Non-IP transports (unix sockets) are local by definition.
+		return true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, n := range a.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if len(a.allow) == 0 {
+		return true
+	}
+	for _, n := range a.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/xml"
+	"sync"
+	"time"
+)
+
+// changeLogLimit bounds the in-memory change log.
+const changeLogLimit = 1000
+
+// ChangeEntry is one successfully applied edit-config item.
+type ChangeEntry struct {
+	Seq       uint64
+	Time      time.Time
+	SessionID uint32
+	Namespace string
+	Item      string // the applied config item, re-rendered as XML
+}
+
+// ChangeLog keeps the most recent applied configuration changes so
+// clients can audit and diff what happened on the box.
+type ChangeLog struct {
+	mu      sync.Mutex
+	nextSeq uint64
+	entries []ChangeEntry
+}
+
+// Append records one applied item and returns its sequence number.
+func (l *ChangeLog) Append(sessionID uint32, ns, item string) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nextSeq++
+	l.entries = append(l.entries, ChangeEntry{
+		Seq:       l.nextSeq,
+		Time:      time.Now(),
+		SessionID: sessionID,
+		Namespace: ns,
+		Item:      item,
+	})
+	if len(l.entries) > changeLogLimit {
+		l.entries = l.entries[len(l.entries)-changeLogLimit:]
+	}
+	return l.nextSeq
+}
+
+// Since returns all entries with Seq > seq, oldest first.
+func (l *ChangeLog) Since(seq uint64) []ChangeEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, e := range l.entries {
+		if e.Seq > seq {
+			out := make([]ChangeEntry, len(l.entries)-i)
+			copy(out, l.entries[i:])
+			return out
+		}
+	}
+	return nil
+}
+
+// RenderElement re-renders a parsed element as XML, e.g. for the
+// change log or template storage.
+func RenderElement(e *Element) string {
+	out, err := xml.Marshal(e)
+	if err != nil {
+		return "<" + e.Local() + ">?</" + e.Local() + ">"
+	}
+	return string(out)
+}
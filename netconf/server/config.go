@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config is the structured server configuration loaded from a JSON
+// file. It complements the command-line flags: flags carry server-wide
+// switches, the file carries per-handler settings, so a minimal
+// deployment can run a slim feature set without patching the binary.
+// A nil Config leaves every handler at its defaults.
+type Config struct {
+	// Handlers maps a canonical namespace to its settings. Namespaces
+	// without an entry register normally.
+	Handlers map[string]HandlerConfig `json:"handlers"`
+}
+
+// HandlerConfig tunes one namespace handler at registration time.
+type HandlerConfig struct {
+	// Enabled defaults to true; an explicit false drops the handler,
+	// like a profile exclusion but chosen by the operator.
+	Enabled *bool `json:"enabled"`
+
+	// Timeout bounds calls into this handler. The -handler-timeouts
+	// flag takes precedence when it names the same namespace.
+	Timeout Duration `json:"timeout"`
+
+	// ReadOnly strips the handler's edit and RPC operations, leaving
+	// state reads only.
+	ReadOnly bool `json:"read_only"`
+
+	// CacheTTL serves repeated gets for this namespace from a cached
+	// reply for the given duration. Edits to the namespace invalidate
+	// the cache. Zero disables caching.
+	CacheTTL Duration `json:"cache_ttl"`
+}
+
+// Duration is a time.Duration that unmarshals from JSON strings like
+// "5s" or "2m".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// LoadConfig reads and parses the structured configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// HandlerEnabled reports whether the namespace may be registered.
+func (c *Config) HandlerEnabled(ns string) bool {
+	if c == nil {
+		return true
+	}
+	hc, ok := c.Handlers[ns]
+	if !ok || hc.Enabled == nil {
+		return true
+	}
+	return *hc.Enabled
+}
+
+// handlerConfig returns the settings for ns, zero when absent.
+func (c *Config) handlerConfig(ns string) HandlerConfig {
+	if c == nil {
+		return HandlerConfig{}
+	}
+	return c.Handlers[ns]
+}
+
+// getCacheEntry is one cached get reply.
+type getCacheEntry struct {
+	data    string
+	expires time.Time
+}
+
+// getCacheKey identifies one get by namespace, target device and
+// rendered filter, so differently filtered requests never share a
+// cached reply and proxy-mode requests never see another device's
+// state.
+func getCacheKey(ctx *Context, call *Call) string {
+	key := call.Namespace + "\x00" + ctx.Device
+	if call.Elem != nil {
+		key += "\x00" + RenderElement(call.Elem)
+	}
+	return key
+}
+
+// cachedGet returns the unexpired cached reply for key, if any.
+func (s *Server) cachedGet(key string) (string, bool) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	e, ok := s.getCache[key]
+	if !ok || time.Now().After(e.expires) {
+		return "", false
+	}
+	return e.data, true
+}
+
+// storeGet caches one get reply for ttl.
+func (s *Server) storeGet(key, data string, ttl time.Duration) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if s.getCache == nil {
+		s.getCache = make(map[string]getCacheEntry)
+	}
+	s.getCache[key] = getCacheEntry{data: data, expires: time.Now().Add(ttl)}
+}
+
+// InvalidateGetCache drops cached get replies: every entry when ns is
+// empty, otherwise the entries for that namespace across all devices.
+// It backs the <refresh-cache> RPC, which must flush the reply cache
+// along with the handler-side tables or reads keep serving
+// pre-refresh state for the full TTL.
+func (s *Server) InvalidateGetCache(ns string) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if ns == "" {
+		s.getCache = nil
+		return
+	}
+	for key := range s.getCache {
+		if strings.HasPrefix(key, ns+"\x00") {
+			delete(s.getCache, key)
+		}
+	}
+}
+
+// invalidateGets drops the cached replies for ns on one device after a
+// successful edit, so reads never serve pre-edit state for the full
+// TTL. Other devices' entries for the namespace stay valid: an edit in
+// proxy mode only touches the device it was addressed to.
+func (s *Server) invalidateGets(ns, device string) {
+	prefix := ns + "\x00" + device
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	for key := range s.getCache {
+		if key == prefix || strings.HasPrefix(key, prefix+"\x00") {
+			delete(s.getCache, key)
+		}
+	}
+}
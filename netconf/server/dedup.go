@@ -0,0 +1,53 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// dedupTTL is how long a completed edit-config reply is remembered for
+// retries carrying the same idempotency token.
+const dedupTTL = 5 * time.Minute
+
+// dedupCache remembers recent edit-config replies keyed by the
+// client-supplied idempotency token, so a client that times out and
+// retries the same request gets the original reply instead of a second
+// apply. Each session owns its cache: tokens are opaque to the server
+// and only meaningful to the client that issued them, so they must
+// never collide across sessions.
+type dedupCache struct {
+	mu      sync.Mutex
+	entries map[string]dedupEntry
+}
+
+type dedupEntry struct {
+	reply string
+	when  time.Time
+}
+
+// lookup returns the cached reply for token, if any.
+func (c *dedupCache) lookup(token string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[token]
+	if !ok || time.Since(e.when) > dedupTTL {
+		return "", false
+	}
+	return e.reply, true
+}
+
+// store remembers the reply for token and prunes expired entries.
+func (c *dedupCache) store(token, reply string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]dedupEntry)
+	}
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.Sub(e.when) > dedupTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[token] = dedupEntry{reply: reply, when: now}
+}
@@ -0,0 +1,54 @@
+package server
+
+import "fmt"
+
+// Standard rpc-error type values (RFC 6241 section 4.3).
+const (
+	ErrTypeRPC         = "rpc"
+	ErrTypeProtocol    = "protocol"
+	ErrTypeApplication = "application"
+)
+
+// RPCError is the error type handlers return; the dispatcher renders it
+// as an <rpc-error> element. Any other error is reported as a generic
+// operation-failed application error.
+type RPCError struct {
+	Type     string // one of the ErrType constants
+	Tag      string // e.g. "invalid-value", "operation-failed"
+	Severity string // "error" unless set otherwise
+	Message  string
+	Path     string // optional element path for <error-path>
+	Info     string // optional inner XML for <error-info>
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s/%s: %s", e.Type, e.Tag, e.Message)
+}
+
+// InvalidValue reports a malformed or out-of-range value in the request.
+func InvalidValue(format string, args ...any) *RPCError {
+	return &RPCError{Type: ErrTypeApplication, Tag: "invalid-value", Message: fmt.Sprintf(format, args...)}
+}
+
+// OperationFailed reports a request that was well formed but could not
+// be carried out.
+func OperationFailed(format string, args ...any) *RPCError {
+	return &RPCError{Type: ErrTypeApplication, Tag: "operation-failed", Message: fmt.Sprintf(format, args...)}
+}
+
+// OperationNotSupported reports a request for functionality the server
+// does not implement.
+func OperationNotSupported(format string, args ...any) *RPCError {
+	return &RPCError{Type: ErrTypeApplication, Tag: "operation-not-supported", Message: fmt.Sprintf(format, args...)}
+}
+
+// DataMissing reports a reference to configuration or state that does
+// not exist on the device.
+func DataMissing(format string, args ...any) *RPCError {
+	return &RPCError{Type: ErrTypeApplication, Tag: "data-missing", Message: fmt.Sprintf(format, args...)}
+}
+
+// MissingElement reports a request that lacks a required element.
+func MissingElement(name string) *RPCError {
+	return &RPCError{Type: ErrTypeProtocol, Tag: "missing-element", Message: fmt.Sprintf("required element <%s> is missing", name)}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// IntentItem is one persisted configuration item.
+type IntentItem struct {
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	XML       string `json:"xml"`
+}
+
+// IntentStore persists the applied configuration intent to a file so
+// it can be re-applied after the gateway (or the Miyagi daemon behind
+// it) restarts. Items are keyed by namespace plus the item's natural
+// key so re-configuring an object replaces its stored intent.
+type IntentStore struct {
+	mu    sync.Mutex
+	path  string
+	items map[string]IntentItem
+	order []string
+}
+
+// OpenIntentStore loads (or creates) the intent file at path.
+func OpenIntentStore(path string) (*IntentStore, error) {
+	st := &IntentStore{path: path, items: make(map[string]IntentItem)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("intent: %w", err)
+	}
+	var items []IntentItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("intent: parse %s: %w", path, err)
+	}
+	for _, it := range items {
+		k := it.Namespace + "|" + it.Key
+		if _, seen := st.items[k]; !seen {
+			st.order = append(st.order, k)
+		}
+		st.items[k] = it
+	}
+	return st, nil
+}
+
+// Record stores or removes the intent for one applied item.
+func (st *IntentStore) Record(item *Element) error {
+	if st == nil {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	key := itemKey(item)
+	k := item.Namespace() + "|" + key
+	if item.Attr("operation") == "delete" || item.ChildText("operation") == "delete" {
+		if _, ok := st.items[k]; ok {
+			delete(st.items, k)
+			for i, o := range st.order {
+				if o == k {
+					st.order = append(st.order[:i], st.order[i+1:]...)
+					break
+				}
+			}
+		}
+	} else {
+		if _, seen := st.items[k]; !seen {
+			st.order = append(st.order, k)
+		}
+		st.items[k] = IntentItem{Namespace: item.Namespace(), Key: key, XML: RenderElement(item)}
+	}
+	return st.saveLocked()
+}
+
+// IsCurrent reports whether item is byte-identical to the intent
+// already stored under its key, i.e. applying it would be a no-op.
+// Deletes are never considered current.
+func (st *IntentStore) IsCurrent(item *Element) bool {
+	if st == nil {
+		return false
+	}
+	if item.Attr("operation") == "delete" || item.ChildText("operation") == "delete" {
+		return false
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	stored, ok := st.items[item.Namespace()+"|"+itemKey(item)]
+	return ok && stored.XML == RenderElement(item)
+}
+
+// Items returns the stored intent in application order.
+func (st *IntentStore) Items() []IntentItem {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	out := make([]IntentItem, 0, len(st.order))
+	for _, k := range st.order {
+		out = append(out, st.items[k])
+	}
+	return out
+}
+
+func (st *IntentStore) saveLocked() error {
+	items := make([]IntentItem, 0, len(st.order))
+	for _, k := range st.order {
+		items = append(items, st.items[k])
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := st.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, st.path)
+}
+
+// itemKey derives the natural key of a config item: its element name
+// plus the first identifying child present.
+func itemKey(item *Element) string {
+	key := item.Local()
+	for _, id := range []string{"name", "id", "prefix", "ifname"} {
+		if v := item.ChildText(id); v != "" {
+			return key + "/" + v
+		}
+	}
+	return key
+}
+
+// RestoreIntent re-applies every stored item through the registered
+// handlers; failures are logged and skipped so one bad item does not
+// block the rest of the restore.
+func (s *Server) RestoreIntent() {
+	if s.Intent == nil {
+		return
+	}
+	ctx := s.BackgroundContext()
+	for _, it := range s.Intent.Items() {
+		item, err := ParseElement([]byte(it.XML))
+		if err != nil {
+			logf("intent: parse %s/%s: %v", it.Namespace, it.Key, err)
+			continue
+		}
+		h := s.Handler(it.Namespace)
+		if h == nil || h.Edit == nil {
+			logf("intent: no handler for %s", it.Namespace)
+			continue
+		}
+		if err := h.Edit(ctx, item); err != nil {
+			logf("intent: re-apply %s/%s: %v", it.Namespace, it.Key, err)
+		}
+	}
+}
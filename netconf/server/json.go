@@ -0,0 +1,53 @@
+package server
+
+import "encoding/json"
+
+// ElementToJSON converts a parsed XML element into a JSON-marshalable
+// value: text-only elements become strings, attributes get an "@"
+// prefix, and repeated child names collapse into arrays.
+func ElementToJSON(e *Element) any {
+	if len(e.Children) == 0 && len(e.Attrs) == 0 {
+		return trimSpace(e.Text)
+	}
+	obj := make(map[string]any)
+	for _, a := range e.Attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		obj["@"+a.Name.Local] = a.Value
+	}
+	counts := make(map[string]int)
+	for i := range e.Children {
+		counts[e.Children[i].XMLName.Local]++
+	}
+	for i := range e.Children {
+		c := &e.Children[i]
+		v := ElementToJSON(c)
+		name := c.XMLName.Local
+		if counts[name] > 1 {
+			arr, _ := obj[name].([]any)
+			obj[name] = append(arr, v)
+		} else {
+			obj[name] = v
+		}
+	}
+	if len(obj) == 0 {
+		return trimSpace(e.Text)
+	}
+	return obj
+}
+
+// marshalElementJSON renders an XML payload (one or more sibling
+// elements) as a JSON object keyed by element name.
+func marshalElementJSON(payload string) (string, error) {
+	// Wrap so sibling elements parse as one document.
+	root, err := ParseElement([]byte("<root>" + payload + "</root>"))
+	if err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(ElementToJSON(root))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"fmt"
+	"time"
+)
+
+// CallOp names which handler operation a Call targets.
+type CallOp string
+
+const (
+	CallGet  CallOp = "get"
+	CallEdit CallOp = "edit"
+	CallRPC  CallOp = "rpc"
+)
+
+// Call describes one handler invocation in a uniform shape, so
+// middleware can observe gets, edits and custom RPCs alike. Elem is
+// the filter subtree, config item or operation element respectively
+// (nil for an unfiltered get).
+type Call struct {
+	Op        CallOp
+	Namespace string
+	Elem      *Element
+}
+
+// HandlerFunc is the unified form of one handler invocation. Edits
+// return an empty payload; gets and RPCs return their XML.
+type HandlerFunc func(ctx *Context, call *Call) (string, error)
+
+// Middleware wraps a HandlerFunc with cross-cutting behavior —
+// authorization, auditing, timing, validation, caching — without
+// touching the per-namespace handlers themselves.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Use appends middleware to the chain. The first middleware registered
+// is outermost: it sees the call first and the result last. Register
+// middleware before serving; the chain is not synchronized.
+func (s *Server) Use(mw ...Middleware) {
+	s.middleware = append(s.middleware, mw...)
+}
+
+// invoke routes one handler call through the middleware chain. It owns
+// the per-namespace timeout, so middleware observes the same deadline
+// the handler does, and the global backend concurrency limit.
+func (s *Server) invoke(ctx *Context, call *Call) (string, error) {
+	fn := s.baseHandlerFunc
+	for i := len(s.middleware) - 1; i >= 0; i-- {
+		fn = s.middleware[i](fn)
+	}
+	hctx, cancel := s.handlerCtx(ctx, call.Namespace)
+	defer cancel()
+	if err := s.acquireBackend(hctx); err != nil {
+		return "", err
+	}
+	defer s.releaseBackend()
+	cacheTTL := time.Duration(s.Config.handlerConfig(call.Namespace).CacheTTL)
+	var cacheKey string
+	if call.Op == CallGet && cacheTTL > 0 {
+		cacheKey = getCacheKey(ctx, call)
+		if data, ok := s.cachedGet(cacheKey); ok {
+			return data, nil
+		}
+	}
+	start := time.Now()
+	out, err := fn(hctx, call)
+	s.recordTiming(call, time.Since(start), err)
+	if err == nil {
+		switch {
+		case cacheKey != "":
+			s.storeGet(cacheKey, out, cacheTTL)
+		case call.Op == CallEdit:
+			s.invalidateGets(call.Namespace, ctx.Device)
+		}
+	}
+	return out, err
+}
+
+// acquireBackend takes one slot of the global backend concurrency
+// budget, waiting until the handler deadline at most.
+func (s *Server) acquireBackend(ctx *Context) error {
+	if s.MaxBackendCalls <= 0 {
+		return nil
+	}
+	s.backendOnce.Do(func() { s.backendSem = make(chan struct{}, s.MaxBackendCalls) })
+	select {
+	case s.backendSem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return &RPCError{Type: ErrTypeApplication, Tag: "resource-denied",
+			Message: fmt.Sprintf("backend concurrency limit (%d) reached", s.MaxBackendCalls)}
+	}
+}
+
+func (s *Server) releaseBackend() {
+	if s.backendSem != nil {
+		<-s.backendSem
+	}
+}
+
+// baseHandlerFunc is the innermost HandlerFunc: it resolves the
+// registered handler and calls the operation the Call names.
+func (s *Server) baseHandlerFunc(ctx *Context, call *Call) (string, error) {
+	h := s.Handler(call.Namespace)
+	switch call.Op {
+	case CallGet:
+		if h == nil || h.Get == nil {
+			return "", OperationNotSupported("no get handler for namespace %q", call.Namespace)
+		}
+		return h.Get(ctx, call.Elem)
+	case CallEdit:
+		if h == nil || h.Edit == nil {
+			return "", OperationNotSupported("no edit handler for namespace %q", call.Namespace)
+		}
+		return "", h.Edit(ctx, call.Elem)
+	case CallRPC:
+		if h == nil || h.RPC == nil {
+			return "", OperationNotSupported("no rpc handler for namespace %q", call.Namespace)
+		}
+		return h.RPC(ctx, call.Elem)
+	default:
+		return "", OperationFailed("unknown call op %q", call.Op)
+	}
+}
+
+// SlowCallLog returns middleware that logs handler calls taking longer
+// than threshold, identifying the operation and namespace.
+func SlowCallLog(threshold time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context, call *Call) (string, error) {
+			start := time.Now()
+			out, err := next(ctx, call)
+			if elapsed := time.Since(start); elapsed > threshold {
+				logf("slow %s %s: %s", call.Op, call.Namespace, elapsed)
+			}
+			return out, err
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/rahulrock213/switch/netconf/miyagi"
+)
+
+// MiyagiErrorMapping describes how one Miyagi status code surfaces to
+// NETCONF clients: the rpc-error tag to use and a human explanation
+// that replaces the daemon's terse "code: N" text.
+type MiyagiErrorMapping struct {
+	Status  int    `json:"status"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// miyagiErrorTags maps the well-known daemon status codes. Codes not
+// listed here fall back to operation-failed. The table can be extended
+// (or overridden) per deployment with LoadMiyagiErrorMap.
+var (
+	miyagiErrMu     sync.Mutex
+	miyagiErrorTags = map[int]MiyagiErrorMapping{
+		1: {Tag: "operation-failed", Message: "internal device error"},
+		2: {Tag: "invalid-value", Message: "the device rejected an argument"},
+		3: {Tag: "data-missing", Message: "no such object on the device"},
+		4: {Tag: "data-exists", Message: "the object already exists on the device"},
+		5: {Tag: "resource-denied", Message: "the device table is full"},
+		6: {Tag: "access-denied", Message: "the daemon denied access to this operation"},
+		7: {Tag: "operation-not-supported", Message: "the daemon does not implement this operation"},
+	}
+)
+
+// LoadMiyagiErrorMap merges mappings from a JSON file (an array of
+// {status, tag, message} objects, the same registry format the UID
+// tables use) into the built-in table, letting deployments teach the
+// gateway about device-specific status codes.
+func LoadMiyagiErrorMap(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var mappings []MiyagiErrorMapping
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	miyagiErrMu.Lock()
+	defer miyagiErrMu.Unlock()
+	for _, m := range mappings {
+		if m.Tag == "" {
+			return fmt.Errorf("%s: status %d has no tag", path, m.Status)
+		}
+		miyagiErrorTags[m.Status] = m
+	}
+	return nil
+}
+
+// mapMiyagiError converts a raw handler error into the RPCError the
+// reply renderer needs: known Miyagi status codes get their mapped tag
+// and explanation (with the daemon detail preserved), everything else
+// stays a generic operation-failed.
+func mapMiyagiError(err error) *RPCError {
+	var me *miyagi.Error
+	if !errors.As(err, &me) {
+		return OperationFailed("%s", err.Error())
+	}
+	miyagiErrMu.Lock()
+	m, ok := miyagiErrorTags[me.Status]
+	miyagiErrMu.Unlock()
+	if !ok {
+		return OperationFailed("%s", me.Error())
+	}
+	return &RPCError{
+		Type:    ErrTypeApplication,
+		Tag:     m.Tag,
+		Message: fmt.Sprintf("%s (%s, status %d: %s)", m.Message, me.UID, me.Status, me.Msg),
+	}
+}
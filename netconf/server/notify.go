@@ -0,0 +1,134 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// Streams lists the named notification streams the server exposes.
+// NETCONF is the default stream carrying everything not claimed by a
+// more specific stream.
+var Streams = []string{"NETCONF", "CONFIG", "LINK", "ENVIRONMENT"}
+
+// replayBufferSize bounds the per-stream replay buffer; once full the
+// oldest events are dropped.
+const replayBufferSize = 512
+
+// knownStream reports whether name is one of the advertised streams.
+func knownStream(name string) bool {
+	for _, s := range Streams {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is one event published on the server's event bus.
+type Notification struct {
+	Stream string    // stream name, e.g. "NETCONF"
+	Time   time.Time // event time
+	XML    string    // event payload (one XML element)
+}
+
+// NotificationSink receives every published notification. Sinks must
+// not block; slow transports should buffer internally.
+type NotificationSink interface {
+	Publish(n Notification)
+}
+
+// AddSink attaches a notification sink.
+func (s *Server) AddSink(sink NotificationSink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sinks = append(s.sinks, sink)
+}
+
+// RemoveSink detaches a previously attached sink; events published
+// after it returns are no longer delivered to it.
+func (s *Server) RemoveSink(sink NotificationSink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, sk := range s.sinks {
+		if sk == sink {
+			s.sinks = append(s.sinks[:i], s.sinks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Notify publishes an event XML element on the given stream. It is
+// delivered to external sinks and to every session subscribed to the
+// stream; in-session delivery only takes the write lock, so RPC
+// processing interleaves freely with notifications.
+func (s *Server) Notify(stream, eventXML string) {
+	n := Notification{Stream: stream, Time: time.Now(), XML: eventXML}
+	s.mu.Lock()
+	if s.replay == nil {
+		s.replay = make(map[string][]Notification)
+	}
+	buf := append(s.replay[stream], n)
+	if len(buf) > replayBufferSize {
+		buf = buf[len(buf)-replayBufferSize:]
+	}
+	s.replay[stream] = buf
+	sinks := append([]NotificationSink(nil), s.sinks...)
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+	for _, sink := range sinks {
+		sink.Publish(n)
+	}
+	frame := NotificationElement(n)
+	for _, sess := range sessions {
+		if sess.wantsStream(stream) {
+			sess.send(frame)
+		}
+	}
+}
+
+// replaySince returns the buffered events on stream with an event time
+// at or after since, oldest first.
+func (s *Server) replaySince(stream string, since time.Time) []Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Notification
+	for _, n := range s.replay[stream] {
+		if !n.Time.Before(since) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// netconfNotifNS is the ietf-netconf-notifications module namespace
+// (RFC 6470) used for the session lifecycle events.
+const netconfNotifNS = "urn:ietf:params:xml:ns:yang:ietf-netconf-notifications"
+
+// notifySessionEvent publishes a netconf-session-start or
+// netconf-session-end event on the NETCONF stream. Sessions on the SSH
+// transport report their authenticated username; transports without
+// authentication (plain TCP, unix socket) report the fixed "unknown"
+// the monitoring side expects from this gateway.
+func (s *Server) notifySessionEvent(event string, sess *Session) {
+	host := sess.conn.RemoteAddr().String()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	user := sess.user
+	if user == "" {
+		user = "unknown"
+	}
+	s.Notify("NETCONF", fmt.Sprintf(
+		"<%s xmlns=%q><username>%s</username><session-id>%d</session-id><source-host>%s</source-host></%s>",
+		event, netconfNotifNS, EscapeXML(user), sess.ID, EscapeXML(host), event))
+}
+
+// NotificationElement renders n as a NETCONF <notification> element.
+func NotificationElement(n Notification) string {
+	return fmt.Sprintf(`<notification xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"><eventTime>%s</eventTime>%s</notification>`,
+		n.Time.UTC().Format(time.RFC3339), n.XML)
+}
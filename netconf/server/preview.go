@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rahulrock213/switch/netconf/miyagi"
+)
+
+// previewOp is one backend operation a <preview> run would execute.
+type previewOp struct {
+	UID string
+	Arg string
+}
+
+// previewCaller wraps the real Miyagi caller for dry runs: read calls
+// (UIDs ending in .get) pass through so handlers can still validate
+// against live state, while mutating calls are recorded in order and
+// answered with a null payload instead of reaching the device.
+type previewCaller struct {
+	real miyagi.Caller
+
+	mu  sync.Mutex
+	ops []previewOp
+}
+
+func (p *previewCaller) Call(ctx context.Context, uid string, arg any) (json.RawMessage, error) {
+	if strings.HasSuffix(uid, ".get") {
+		return p.real.Call(ctx, uid, arg)
+	}
+	p.mu.Lock()
+	p.ops = append(p.ops, previewOp{UID: uid, Arg: redactArg(arg)})
+	p.mu.Unlock()
+	return json.RawMessage("null"), nil
+}
+
+// secretKeys are argument key substrings whose values are never echoed
+// back in a preview.
+var secretKeys = []string{"password", "secret", "community", "passphrase"}
+
+// redactArg renders the call argument as JSON with secret values
+// replaced, recursing through nested objects and arrays.
+func redactArg(arg any) string {
+	if arg == nil {
+		return "null"
+	}
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return fmt.Sprintf("%q", fmt.Sprint(arg))
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	redacted, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return string(data)
+	}
+	return string(redacted)
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if isSecretKey(k) {
+				t[k] = "<redacted>"
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+		return t
+	case []any:
+		for i, val := range t {
+			t[i] = redactValue(val)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePreview serves the vendor <preview> RPC: it runs the wrapped
+// <config> through the edit handlers against the recording caller and
+// returns the ordered backend operations instead of applying them.
+// Nothing is written to the change log or the intent store. Unlike a
+// real edit-config, items are applied strictly in document order so the
+// reported sequence is deterministic.
+func (s *Server) handlePreview(ctx *Context, msgID string, op *Element) string {
+	config := op.Child("config")
+	if config == nil {
+		return ErrorReply(msgID, MissingElement("config"))
+	}
+	rec := &previewCaller{real: ctx.Miyagi}
+	pctx := *ctx
+	pctx.Miyagi = rec
+	for i := range config.Children {
+		item := &config.Children[i]
+		h := s.Handler(item.Namespace())
+		if h == nil || h.Edit == nil {
+			return ErrorReply(msgID, OperationNotSupported("no edit handler for namespace %q", item.Namespace()))
+		}
+		// Mirror the real apply path: an item is only elided when the
+		// operator opted into no-op skipping and the device still
+		// reflects it — the same gate applyItems uses — so the plan
+		// never hides operations a real edit-config would run.
+		if s.SkipNoopEdits && s.Intent.IsCurrent(item) && s.itemReflected(ctx, item) {
+			continue
+		}
+		hctx, cancel := s.handlerCtx(&pctx, item.Namespace())
+		err := h.Edit(hctx, item)
+		cancel()
+		if err != nil {
+			return ErrorReply(msgID, err)
+		}
+	}
+	var b strings.Builder
+	b.WriteString("<planned-operations>")
+	for _, o := range rec.ops {
+		fmt.Fprintf(&b, "<operation><uid>%s</uid><arg>%s</arg></operation>",
+			EscapeXML(o.UID), EscapeXML(o.Arg))
+	}
+	b.WriteString("</planned-operations>")
+	return RPCReply(msgID, b.String())
+}
@@ -0,0 +1,22 @@
+package server
+
+// Profile restricts which handler namespaces a given hardware model
+// exposes, so one binary can serve several SKUs without advertising
+// features the device cannot deliver. A nil Profile enables every
+// namespace.
+type Profile struct {
+	// Model is the device model the profile was selected for, kept
+	// for logging.
+	Model string
+
+	// Disabled holds the namespaces this model must not expose.
+	Disabled map[string]bool
+}
+
+// Enabled reports whether the namespace may be registered.
+func (p *Profile) Enabled(ns string) bool {
+	if p == nil {
+		return true
+	}
+	return !p.Disabled[ns]
+}
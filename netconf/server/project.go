@@ -0,0 +1,57 @@
+package server
+
+// projectReply narrows one handler's get reply to the leaves the
+// filter selected. Empty leaves in a subtree filter (e.g. <name/> and
+// <oper-status/> under a list entry) are selection nodes per RFC 6241;
+// handlers build their full subtree and the dispatcher prunes
+// everything the filter did not ask for, so high-frequency pollers can
+// cut response size without per-handler support. Filters without any
+// empty leaf keep today's behavior of returning every field.
+func projectReply(data string, filter *Element) string {
+	if filter == nil || !hasLeafSelection(filter) {
+		return data
+	}
+	root, err := ParseElement([]byte(data))
+	if err != nil {
+		// The handler's reply is a fragment the projector cannot
+		// parse (e.g. sibling roots); return it unmodified.
+		return data
+	}
+	projectElement(root, filter)
+	return RenderElement(root)
+}
+
+// hasLeafSelection reports whether the filter contains an empty leaf,
+// i.e. a node with no children and no content match.
+func hasLeafSelection(filter *Element) bool {
+	for i := range filter.Children {
+		c := &filter.Children[i]
+		if len(c.Children) == 0 && trimSpace(c.Text) == "" {
+			return true
+		}
+		if hasLeafSelection(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectElement prunes node's children to those the filter names,
+// recursing into matching containers. A filter node without children
+// selects its whole subtree.
+func projectElement(node, filter *Element) {
+	if len(filter.Children) == 0 {
+		return
+	}
+	kept := node.Children[:0]
+	for i := range node.Children {
+		c := &node.Children[i]
+		f := filter.Child(c.Local())
+		if f == nil {
+			continue
+		}
+		projectElement(c, f)
+		kept = append(kept, *c)
+	}
+	node.Children = kept
+}
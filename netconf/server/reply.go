@@ -0,0 +1,35 @@
+package server
+
+import (
+	"github.com/rahulrock213/switch/netconf/netconfutil"
+)
+
+const baseNS = netconfutil.BaseNS
+
+// RPCReply wraps inner XML in an <rpc-reply> element for the given
+// message-id.
+func RPCReply(msgID, inner string) string {
+	return netconfutil.RPCReply(msgID, inner)
+}
+
+// OKReply builds the <ok/> reply used for successful edits.
+func OKReply(msgID string) string {
+	return netconfutil.OKReply(msgID)
+}
+
+// DataReply wraps inner XML in <rpc-reply><data>...</data></rpc-reply>.
+func DataReply(msgID, inner string) string {
+	return netconfutil.DataReply(msgID, inner)
+}
+
+// ErrorReply renders err as an <rpc-error> reply. Miyagi errors with a
+// well-known status code are translated to their mapped error-tag;
+// anything else that is not an *RPCError becomes a generic
+// operation-failed application error.
+func ErrorReply(msgID string, err error) string {
+	rpcErr, ok := err.(*RPCError)
+	if !ok {
+		rpcErr = mapMiyagiError(err)
+	}
+	return RPCReply(msgID, netconfutil.RenderRPCError(rpcErr.Type, rpcErr.Tag, rpcErr.Severity, rpcErr.Message, rpcErr.Path, rpcErr.Info))
+}
@@ -0,0 +1,743 @@
+// Package server implements the NETCONF front end of the gateway: it
+// terminates NETCONF sessions, parses RPC frames, and dispatches
+// <get>/<edit-config> payloads to per-namespace handlers that translate
+// them into Miyagi API calls.
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rahulrock213/switch/netconf/miyagi"
+)
+
+// GetFunc builds the <data> payload for a <get> whose filter selected
+// the handler's namespace. The filter element is the subtree node from
+// the request, or nil when the namespace was selected without one.
+type GetFunc func(ctx *Context, filter *Element) (string, error)
+
+// EditFunc applies one <edit-config> config item belonging to the
+// handler's namespace.
+type EditFunc func(ctx *Context, item *Element) error
+
+// RPCFunc serves a custom RPC whose operation element lives in the
+// handler's namespace. It returns the inner XML of the rpc-reply.
+type RPCFunc func(ctx *Context, op *Element) (string, error)
+
+// Handler implements the NETCONF operations for one XML namespace.
+type Handler struct {
+	Namespace string
+	// Aliases lists alternate namespace URIs that resolve to this
+	// handler, for controllers using a different naming scheme. Only
+	// the canonical Namespace is advertised in the hello.
+	Aliases []string
+	Get     GetFunc
+	Edit    EditFunc
+	RPC     RPCFunc
+}
+
+// Context carries the per-RPC state passed to handlers.
+type Context struct {
+	context.Context
+	Server  *Server
+	Session *Session
+	Miyagi  miyagi.Caller
+	// Device names the proxied device this request addresses; empty
+	// for the default device.
+	Device string
+}
+
+// HasFeature reports whether the daemon supports the given UID.
+func (c *Context) HasFeature(uid string) bool {
+	return c.Server.Features.Has(uid)
+}
+
+// RequireFeature returns an operation-not-supported error when the
+// daemon lacks the given UID.
+func (c *Context) RequireFeature(uid string) error {
+	if !c.HasFeature(uid) {
+		return OperationNotSupported("device does not support %s", uid)
+	}
+	return nil
+}
+
+// Server is the NETCONF gateway. It owns the southbound Miyagi
+// connection and the set of registered namespace handlers.
+type Server struct {
+	Miyagi miyagi.Caller
+
+	// Features records which Miyagi UIDs the daemon supports; nil
+	// means unprobed (assume all).
+	Features *miyagi.FeatureSet
+
+	// Profile restricts the namespaces exposed for the device model;
+	// nil enables everything. Set it before registering handlers.
+	Profile *Profile
+
+	// Config carries the per-handler settings from the structured
+	// configuration file; nil leaves every handler at its defaults.
+	// Set it before registering handlers.
+	Config *Config
+
+	// Changes records successfully applied edit-config items.
+	Changes ChangeLog
+
+	// Intent, when set, persists applied items across restarts.
+	Intent *IntentStore
+
+	// Devices maps device names to their Miyagi connections when the
+	// gateway fronts several switches (proxy mode). Requests select a
+	// device with a device="name" attribute on <rpc>; without one the
+	// default Miyagi connection is used.
+	Devices map[string]miyagi.Caller
+
+	// ACL filters connecting source addresses before the NETCONF
+	// exchange starts; nil permits every source.
+	ACL *AccessList
+
+	// AllowCLIPassthrough gates the raw device-CLI RPC; it stays off
+	// unless the operator explicitly enables it.
+	AllowCLIPassthrough bool
+
+	// AutoCreateVlans makes port VLAN assignments create missing
+	// VLANs on the fly instead of failing with data-missing.
+	AutoCreateVlans bool
+
+	// SkipNoopEdits skips edit items that are byte-identical to the
+	// stored intent, after re-reading device state to confirm it still
+	// reflects them. Off by default: intent alone cannot prove an edit
+	// is a no-op, because an out-of-band change (CLI, web UI) leaves
+	// the stored intent stale while the device diverges.
+	SkipNoopEdits bool
+
+	// ApplyWorkers bounds how many independent item groups of one
+	// edit-config apply concurrently; 0 uses defaultApplyWorkers.
+	ApplyWorkers int
+
+	// StrictMessageIDs, when true, requires numeric message-ids that
+	// strictly increase within a session. Duplicate or out-of-order
+	// ids are rejected with an rpc-error, which protects against
+	// replayed frames and guarantees unambiguous id-based correlation
+	// for controllers that rely on it.
+	StrictMessageIDs bool
+
+	// StrictReplies, when true, prefixes every outbound frame with an
+	// XML declaration. Replies already use the standard
+	// <rpc-reply><data> envelope with the base namespace; the
+	// declaration is the remaining piece strict clients (ncclient)
+	// expect. Off by default to keep byte-identical output for
+	// existing consumers.
+	StrictReplies bool
+
+	// TraceFrames, when true, logs every frame received and sent on
+	// each session at debug level. It is expensive and intended for
+	// troubleshooting only.
+	TraceFrames bool
+
+	// HandlerTimeouts bounds how long one handler call may run, per
+	// namespace; DefaultHandlerTimeout applies to namespaces without
+	// an entry. Zero means no limit.
+	HandlerTimeouts       map[string]time.Duration
+	DefaultHandlerTimeout time.Duration
+
+	// WriteTimeout bounds each frame write to a session. A client that
+	// stops draining its receive buffer stalls the write past the
+	// deadline and is dropped, so one stuck controller cannot block
+	// notification fan-out or pin its session goroutines forever.
+	// Zero means no limit.
+	WriteTimeout time.Duration
+
+	// SessionQueueDepth bounds how many parsed RPCs one session may
+	// have waiting; a pipelining client that overruns it gets
+	// resource-denied replies. Zero means the default depth.
+	SessionQueueDepth int
+
+	// MaxBackendCalls caps handler calls running concurrently across
+	// all sessions, so one flooding client cannot monopolize the
+	// Miyagi connection. Excess calls wait until a slot frees or
+	// their handler deadline expires. Zero means no limit.
+	MaxBackendCalls int
+
+	mu         sync.Mutex
+	handlers   map[string]*Handler
+	aliases    map[string]string
+	middleware []Middleware
+	sessions   map[uint32]*Session
+	nextSID    uint32
+
+	startTime   time.Time
+	totalRPCs   atomic.Uint64
+	slowDrops   atomic.Uint64
+	timingMu    sync.Mutex
+	timings     map[timingKey]*handlerStats
+	cacheMu     sync.Mutex
+	getCache    map[string]getCacheEntry
+	backendOnce sync.Once
+	backendSem  chan struct{}
+	sinks       []NotificationSink
+	replay      map[string][]Notification
+
+	listeners []net.Listener
+	closing   bool
+	sessWG    sync.WaitGroup
+}
+
+// New creates a Server that fulfils requests through the given Miyagi
+// caller.
+func New(m miyagi.Caller) *Server {
+	return &Server{
+		Miyagi:    m,
+		handlers:  make(map[string]*Handler),
+		aliases:   make(map[string]string),
+		sessions:  make(map[uint32]*Session),
+		startTime: time.Now(),
+	}
+}
+
+// Register adds a namespace handler. Registering the same namespace
+// twice panics, since that is always a wiring bug.
+func (s *Server) Register(h *Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Profile.Enabled(h.Namespace) {
+		logf("namespace %s disabled by profile for model %s", h.Namespace, s.Profile.Model)
+		return
+	}
+	if !s.Config.HandlerEnabled(h.Namespace) {
+		logf("namespace %s disabled by configuration", h.Namespace)
+		return
+	}
+	if cfg := s.Config.handlerConfig(h.Namespace); cfg.ReadOnly && (h.Edit != nil || h.RPC != nil) {
+		ro := *h
+		ro.Edit, ro.RPC = nil, nil
+		h = &ro
+		logf("namespace %s registered read-only by configuration", h.Namespace)
+	}
+	if timeout := time.Duration(s.Config.handlerConfig(h.Namespace).Timeout); timeout > 0 {
+		if s.HandlerTimeouts == nil {
+			s.HandlerTimeouts = make(map[string]time.Duration)
+		}
+		// The -handler-timeouts flag wins when both name a namespace.
+		if _, ok := s.HandlerTimeouts[h.Namespace]; !ok {
+			s.HandlerTimeouts[h.Namespace] = timeout
+		}
+	}
+	if _, dup := s.handlers[h.Namespace]; dup {
+		panic(fmt.Sprintf("server: duplicate handler for namespace %q", h.Namespace))
+	}
+	s.handlers[h.Namespace] = h
+	for _, alias := range h.Aliases {
+		if _, dup := s.aliases[alias]; dup {
+			panic(fmt.Sprintf("server: duplicate alias %q", alias))
+		}
+		s.aliases[alias] = h.Namespace
+	}
+}
+
+// Handler returns the handler registered for ns, resolving namespace
+// aliases to their canonical handler, or nil.
+func (s *Server) Handler(ns string) *Handler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if canon, ok := s.aliases[ns]; ok {
+		ns = canon
+	}
+	return s.handlers[ns]
+}
+
+// ListenAndServe accepts NETCONF sessions on the given TCP address and
+// serves them until the listener fails.
+func (s *Server) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts NETCONF sessions from ln until Shutdown is called or
+// the listener fails. It may be called once per transport (TCP and the
+// local unix socket); sessions from every listener share one server.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	s.listeners = append(s.listeners, ln)
+	s.mu.Unlock()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closing := s.closing
+			s.mu.Unlock()
+			if closing {
+				return nil
+			}
+			return err
+		}
+		if !s.ACL.Permit(conn.RemoteAddr()) {
+			logf("rejected connection from %s (acl)", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		sess := s.newSession(conn)
+		go func() {
+			defer s.sessWG.Done()
+			sess.serve()
+		}()
+	}
+}
+
+// Shutdown stops accepting sessions, closes every active session and
+// waits for their goroutines (including subscription pushers) to exit,
+// so repeated start/stop cycles leak nothing.
+func (s *Server) Shutdown() {
+	s.mu.Lock()
+	s.closing = true
+	listeners := s.listeners
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+	for _, ln := range listeners {
+		ln.Close()
+	}
+	for _, sess := range sessions {
+		sess.conn.Close()
+	}
+	s.sessWG.Wait()
+}
+
+// Drain stops accepting new sessions and waits for the active ones to
+// end on their own, so a hitless restart serves every in-flight RPC
+// and open session to completion instead of cutting them off. Sessions
+// still open after timeout (idle controllers, long-lived subscription
+// holders) are force-closed the way Shutdown closes them; a zero
+// timeout waits forever.
+func (s *Server) Drain(timeout time.Duration) {
+	s.mu.Lock()
+	s.closing = true
+	listeners := s.listeners
+	s.mu.Unlock()
+	for _, ln := range listeners {
+		ln.Close()
+	}
+	done := make(chan struct{})
+	go func() {
+		s.sessWG.Wait()
+		close(done)
+	}()
+	if timeout > 0 {
+		select {
+		case <-done:
+			return
+		case <-time.After(timeout):
+		}
+		s.mu.Lock()
+		sessions := make([]*Session, 0, len(s.sessions))
+		for _, sess := range s.sessions {
+			sessions = append(sessions, sess)
+		}
+		s.mu.Unlock()
+		logf("drain timed out after %s, closing %d remaining sessions", timeout, len(sessions))
+		for _, sess := range sessions {
+			sess.conn.Close()
+		}
+	}
+	<-done
+}
+
+func (s *Server) newSession(conn net.Conn) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextSID++
+	sess := &Session{ID: s.nextSID, conn: conn, srv: s, started: time.Now()}
+	if uc, ok := conn.(interface{ Username() string }); ok {
+		sess.user = uc.Username()
+	}
+	s.sessions[sess.ID] = sess
+	s.sessWG.Add(1)
+	return sess
+}
+
+func (s *Server) dropSession(sess *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sess.ID)
+}
+
+// dispatch handles one parsed <rpc> element and returns the reply frame.
+func (s *Server) dispatch(sess *Session, rpc *Element) string {
+	msgID := rpc.Attr("message-id")
+	if msgID == "" {
+		return ErrorReply("", &RPCError{Type: ErrTypeRPC, Tag: "missing-attribute", Message: "rpc element has no message-id"})
+	}
+	if len(rpc.Children) == 0 {
+		return ErrorReply(msgID, MissingElement("rpc operation"))
+	}
+	op := &rpc.Children[0]
+	ctx := &Context{Context: context.Background(), Server: s, Session: sess, Miyagi: s.Miyagi}
+	if dev := rpc.Attr("device"); dev != "" {
+		caller, ok := s.Devices[dev]
+		if !ok {
+			return ErrorReply(msgID, InvalidValue("unknown device %q", dev))
+		}
+		ctx.Miyagi = caller
+		ctx.Device = dev
+	}
+	switch op.Local() {
+	case "get", "get-config":
+		return s.dispatchGet(ctx, msgID, op)
+	case "edit-config":
+		// A client may mark an edit as retry-safe with an
+		// idempotency-token attribute on <rpc>: a retry carrying the
+		// same token within the dedup window gets the remembered reply
+		// instead of a second apply. Tokens are scoped to the issuing
+		// session; edits without a token are always applied.
+		if token := rpc.Attr("idempotency-token"); token != "" && sess != nil {
+			if reply, ok := sess.dedup.lookup(token); ok {
+				return reply
+			}
+			reply := s.dispatchEdit(ctx, msgID, op)
+			sess.dedup.store(token, reply)
+			return reply
+		}
+		return s.dispatchEdit(ctx, msgID, op)
+	case "preview":
+		return s.handlePreview(ctx, msgID, op)
+	case "ping-server":
+		return s.handlePing(msgID)
+	case "close-session":
+		sess.closing.Store(true)
+		return OKReply(msgID)
+	case "kill-session":
+		id, err := strconv.ParseUint(op.ChildText("session-id"), 10, 32)
+		if err != nil {
+			return ErrorReply(msgID, InvalidValue("<session-id> must be numeric"))
+		}
+		if uint32(id) == sess.ID {
+			return ErrorReply(msgID, InvalidValue("a session cannot kill itself; use close-session"))
+		}
+		s.mu.Lock()
+		victim := s.sessions[uint32(id)]
+		s.mu.Unlock()
+		if victim == nil {
+			return ErrorReply(msgID, InvalidValue("no session %d", id))
+		}
+		victim.conn.Close()
+		return OKReply(msgID)
+	case "exec", "shell", "command", "system":
+		// Deliberate, helpful rejection: the gateway never executes
+		// arbitrary commands on the switch.
+		return ErrorReply(msgID, &RPCError{
+			Type:    ErrTypeApplication,
+			Tag:     "operation-not-supported",
+			Message: fmt.Sprintf("<%s> is not supported: this gateway does not execute shell commands; use the configuration namespaces or the documented RPCs", op.Local()),
+		})
+	case "create-subscription":
+		stream := op.ChildText("stream")
+		if stream == "" {
+			stream = "NETCONF"
+		}
+		if !knownStream(stream) {
+			return ErrorReply(msgID, InvalidValue("unknown stream %q; available streams: %s", stream, strings.Join(Streams, ", ")))
+		}
+		var since time.Time
+		if st := op.ChildText("startTime"); st != "" {
+			t, err := time.Parse(time.RFC3339, st)
+			if err != nil {
+				return ErrorReply(msgID, InvalidValue("<startTime> must be RFC 3339, got %q", st))
+			}
+			since = t
+		}
+		sess.subscribeStream(stream)
+		if !since.IsZero() {
+			// Replay must arrive after the <ok/> reply, so send both
+			// here and tell the session loop the reply is handled.
+			replayed := s.replaySince(stream, since)
+			sess.send(OKReply(msgID))
+			for _, n := range replayed {
+				sess.send(NotificationElement(n))
+			}
+			sess.send(NotificationElement(Notification{
+				Stream: stream,
+				Time:   time.Now(),
+				XML:    `<replayComplete xmlns="urn:ietf:params:xml:ns:netconf:notification:1.0"/>`,
+			}))
+			return ""
+		}
+		return OKReply(msgID)
+	case "establish-subscription":
+		return s.handleEstablishSubscription(ctx, msgID, op)
+	case "modify-subscription":
+		return s.handleModifySubscription(ctx, msgID, op)
+	case "delete-subscription":
+		return s.handleDeleteSubscription(ctx, msgID, op)
+	default:
+		if h := s.Handler(op.Namespace()); h != nil && h.RPC != nil {
+			inner, err := s.invoke(ctx, &Call{Op: CallRPC, Namespace: op.Namespace(), Elem: op})
+			if err != nil {
+				return ErrorReply(msgID, err)
+			}
+			// Clients may opt into a JSON payload with
+			// encoding="json" on the operation element.
+			if op.Attr("encoding") == "json" {
+				jsonBody, err := marshalElementJSON(inner)
+				if err != nil {
+					return ErrorReply(msgID, OperationFailed("encode reply as json: %v", err))
+				}
+				return RPCReply(msgID, "<json>"+EscapeXML(jsonBody)+"</json>")
+			}
+			return RPCReply(msgID, inner)
+		}
+		return ErrorReply(msgID, OperationNotSupported("unknown operation <%s>", op.Local()))
+	}
+}
+
+// dispatchGet routes each subtree of the filter to the handler owning
+// its namespace and concatenates the results.
+func (s *Server) dispatchGet(ctx *Context, msgID string, op *Element) string {
+	filter := op.Child("filter")
+	if filter == nil || len(filter.Children) == 0 {
+		return s.dispatchGetAll(ctx, msgID)
+	}
+	var data string
+	for i := range filter.Children {
+		sub := &filter.Children[i]
+		part, err := s.invoke(ctx, &Call{Op: CallGet, Namespace: sub.Namespace(), Elem: sub})
+		if err != nil {
+			return ErrorReply(msgID, err)
+		}
+		data += projectReply(part, sub)
+	}
+	return DataReply(msgID, data)
+}
+
+// dispatchGetAll serves an unfiltered <get>: every namespace with a
+// get handler contributes its subtree.
+func (s *Server) dispatchGetAll(ctx *Context, msgID string) string {
+	data, err := s.GetData(ctx, "")
+	if err != nil {
+		return ErrorReply(msgID, err)
+	}
+	return DataReply(msgID, data)
+}
+
+// GetData serves state reads for northbound consumers outside a
+// NETCONF session (the admin API): one namespace's subtree, or every
+// namespace with a get handler when ns is empty, in namespace order so
+// the aggregated tree is stable across requests. Calls run through the
+// normal middleware chain, so caching and timing apply.
+func (s *Server) GetData(ctx *Context, ns string) (string, error) {
+	if ns != "" {
+		return s.invoke(ctx, &Call{Op: CallGet, Namespace: ns})
+	}
+	s.mu.Lock()
+	namespaces := make([]string, 0, len(s.handlers))
+	for n, h := range s.handlers {
+		if h.Get != nil {
+			namespaces = append(namespaces, n)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(namespaces)
+	var data string
+	for _, n := range namespaces {
+		part, err := s.invoke(ctx, &Call{Op: CallGet, Namespace: n})
+		if err != nil {
+			return "", err
+		}
+		data += part
+	}
+	return data, nil
+}
+
+// dispatchEdit routes each item under <config> to the handler owning
+// its namespace. Items are applied in document order; the first failure
+// aborts the remainder.
+func (s *Server) dispatchEdit(ctx *Context, msgID string, op *Element) string {
+	config := op.Child("config")
+	if config == nil {
+		return ErrorReply(msgID, MissingElement("config"))
+	}
+	if err := s.ApplyConfig(ctx, config); err != nil {
+		return ErrorReply(msgID, err)
+	}
+	return OKReply(msgID)
+}
+
+// defaultApplyWorkers bounds the concurrent item groups of one
+// edit-config when the server does not configure ApplyWorkers.
+const defaultApplyWorkers = 8
+
+// ApplyConfig applies every item under a <config> element through the
+// registered edit handlers. Items are grouped by their natural key —
+// namespace plus the identifying child (name, id, prefix, ifname) —
+// so a bulk payload addressing many ports or VLANs in one namespace
+// still fans out. Independent groups run concurrently on a bounded
+// worker pool; items sharing a key, and keyless items in the same
+// namespace, keep their document order. It is used by edit-config
+// dispatch and by deferred apply paths (e.g. scheduled jobs).
+func (s *Server) ApplyConfig(ctx *Context, config *Element) error {
+	// Validate every item up front so a payload with an unknown
+	// namespace fails before anything is applied.
+	groups := make(map[string][]*Element)
+	var order []string
+	for i := range config.Children {
+		item := &config.Children[i]
+		ns := item.Namespace()
+		h := s.Handler(ns)
+		if h == nil || h.Edit == nil {
+			return OperationNotSupported("no edit handler for namespace %q", ns)
+		}
+		key := applyKey(item)
+		if _, seen := groups[key]; !seen {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+	if len(order) == 1 {
+		return s.applyItems(ctx, groups[order[0]])
+	}
+	workers := s.ApplyWorkers
+	if workers <= 0 {
+		workers = defaultApplyWorkers
+	}
+	work := make(chan []*Element)
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for items := range work {
+				if err := s.applyItems(ctx, items); err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, key := range order {
+		work <- groups[key]
+	}
+	close(work)
+	wg.Wait()
+	return firstErr
+}
+
+// applyKey derives the grouping key of one config item: the namespace
+// plus the value of its first identifying child, the same children the
+// intent store keys on. Items without one (and items addressed by
+// ifindex) fall back to the bare namespace, which conservatively keeps
+// them ordered against each other.
+func applyKey(item *Element) string {
+	ns := item.Namespace()
+	for _, id := range []string{"name", "id", "prefix", "ifname"} {
+		if v := item.ChildText(id); v != "" {
+			return ns + "\x00" + v
+		}
+	}
+	return ns
+}
+
+// applyItems applies one namespace's items sequentially.
+func (s *Server) applyItems(ctx *Context, items []*Element) error {
+	for _, item := range items {
+		// An item identical to the stored intent is a candidate no-op,
+		// but only when the operator opted in and the device state
+		// still reflects it; see SkipNoopEdits.
+		if s.SkipNoopEdits && s.Intent.IsCurrent(item) && s.itemReflected(ctx, item) {
+			continue
+		}
+		if _, err := s.invoke(ctx, &Call{Op: CallEdit, Namespace: item.Namespace(), Elem: item}); err != nil {
+			return err
+		}
+		s.Changes.Append(sessID(ctx.Session), item.Namespace(), RenderElement(item))
+		if err := s.Intent.Record(item); err != nil {
+			logf("intent: persist %s: %v", item.Namespace(), err)
+		}
+		s.Notify("CONFIG", fmt.Sprintf(
+			`<config-change xmlns=%q><session-id>%d</session-id><namespace>%s</namespace></config-change>`,
+			baseNS, sessID(ctx.Session), EscapeXML(item.Namespace())))
+	}
+	return nil
+}
+
+// itemReflected re-reads the item's namespace state and reports
+// whether every plain leaf of the item appears in it, the same leaf
+// containment comparison the drift detector uses. Any doubt — no get
+// handler, a read failure — counts as not reflected, so the edit is
+// applied.
+func (s *Server) itemReflected(ctx *Context, item *Element) bool {
+	h := s.Handler(item.Namespace())
+	if h == nil || h.Get == nil {
+		return false
+	}
+	state, err := h.Get(ctx, nil)
+	if err != nil {
+		return false
+	}
+	for i := range item.Children {
+		c := &item.Children[i]
+		// Only plain leaves are comparable against state output;
+		// "operation" is edit-config metadata, not device state.
+		if len(c.Children) > 0 || c.Local() == "operation" {
+			continue
+		}
+		v := strings.TrimSpace(c.Text)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(state, fmt.Sprintf("<%s>%s</%s>", c.Local(), EscapeXML(v), c.Local())) {
+			return false
+		}
+	}
+	return true
+}
+
+// BackgroundContext builds a Context that is not tied to any session,
+// for work the server performs on its own behalf.
+func (s *Server) BackgroundContext() *Context {
+	return &Context{Context: context.Background(), Server: s, Miyagi: s.Miyagi}
+}
+
+// handlerCtx derives a Context bounded by the namespace's configured
+// timeout. The returned cancel must always be called.
+func (s *Server) handlerCtx(ctx *Context, ns string) (*Context, context.CancelFunc) {
+	timeout, ok := s.HandlerTimeouts[ns]
+	if !ok {
+		timeout = s.DefaultHandlerTimeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	inner, cancel := context.WithTimeout(ctx.Context, timeout)
+	bounded := *ctx
+	bounded.Context = inner
+	return &bounded, cancel
+}
+
+func sessID(sess *Session) uint32 {
+	if sess == nil {
+		return 0
+	}
+	return sess.ID
+}
+
+func logf(format string, args ...any) {
+	log.Printf("netconf: "+format, args...)
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"net"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// sshSubsystem is the SSH subsystem name NETCONF runs over (RFC 6242).
+const sshSubsystem = "netconf"
+
+// ServeSSH accepts SSH connections from ln, authenticates each against
+// cfg and serves the "netconf" subsystem channel of every connection as
+// a NETCONF session (RFC 6242). The authenticated SSH username is
+// attached to the session, so lifecycle notifications and audit logs
+// report a real user instead of "unknown". It may run alongside Serve
+// on other listeners; sessions from every transport share one server.
+func (s *Server) ServeSSH(ln net.Listener, cfg *ssh.ServerConfig) error {
+	s.mu.Lock()
+	s.listeners = append(s.listeners, ln)
+	s.mu.Unlock()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closing := s.closing
+			s.mu.Unlock()
+			if closing {
+				return nil
+			}
+			return err
+		}
+		if !s.ACL.Permit(conn.RemoteAddr()) {
+			logf("rejected connection from %s (acl)", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		go s.serveSSHConn(conn, cfg)
+	}
+}
+
+// serveSSHConn runs the SSH handshake on one transport connection and
+// serves its session channels. A failed handshake (bad credentials,
+// protocol mismatch) never reaches the NETCONF layer.
+func (s *Server) serveSSHConn(tconn net.Conn, cfg *ssh.ServerConfig) {
+	sconn, chans, reqs, err := ssh.NewServerConn(tconn, cfg)
+	if err != nil {
+		logf("ssh handshake from %s: %v", tconn.RemoteAddr(), err)
+		tconn.Close()
+		return
+	}
+	defer sconn.Close()
+	go ssh.DiscardRequests(reqs)
+	for newCh := range chans {
+		if newCh.ChannelType() != "session" {
+			newCh.Reject(ssh.UnknownChannelType, "only session channels are supported")
+			continue
+		}
+		ch, chReqs, err := newCh.Accept()
+		if err != nil {
+			logf("ssh channel from %s: %v", sconn.RemoteAddr(), err)
+			continue
+		}
+		go s.serveSSHChannel(sconn, ch, chReqs)
+	}
+}
+
+// serveSSHChannel answers requests on one session channel. The first
+// "subsystem" request naming netconf starts the NETCONF session over
+// the channel; everything else (shell, exec, pty-req) is refused, since
+// this gateway only speaks NETCONF.
+func (s *Server) serveSSHChannel(sconn *ssh.ServerConn, ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+	started := false
+	for req := range reqs {
+		// The subsystem name follows a 4-byte length prefix.
+		accept := !started && req.Type == "subsystem" &&
+			len(req.Payload) > 4 && string(req.Payload[4:]) == sshSubsystem
+		if req.WantReply {
+			req.Reply(accept, nil)
+		}
+		if !accept {
+			continue
+		}
+		started = true
+		sess := s.newSession(&sshChannelConn{
+			Channel: ch,
+			laddr:   sconn.LocalAddr(),
+			raddr:   sconn.RemoteAddr(),
+			user:    sconn.User(),
+		})
+		go func() {
+			defer s.sessWG.Done()
+			sess.serve()
+		}()
+	}
+}
+
+// sshChannelConn adapts an SSH channel to the net.Conn the session
+// layer reads and writes. SSH channels carry no per-write deadlines, so
+// the deadline setters are no-ops; a stalled SSH client is bounded by
+// the TCP layer rather than WriteTimeout.
+type sshChannelConn struct {
+	ssh.Channel
+	laddr, raddr net.Addr
+	user         string
+}
+
+func (c *sshChannelConn) LocalAddr() net.Addr              { return c.laddr }
+func (c *sshChannelConn) RemoteAddr() net.Addr             { return c.raddr }
+func (c *sshChannelConn) SetDeadline(time.Time) error      { return nil }
+func (c *sshChannelConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *sshChannelConn) SetWriteDeadline(time.Time) error { return nil }
+
+// Username reports the authenticated SSH user to the session layer.
+func (c *sshChannelConn) Username() string { return c.user }
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SessionInfo is a point-in-time snapshot of one active session.
+type SessionInfo struct {
+	ID         uint32    `json:"id"`
+	Remote     string    `json:"remote"`
+	Username   string    `json:"username,omitempty"`
+	Started    time.Time `json:"started"`
+	RPCs       uint64    `json:"rpcs"`
+	ClientCaps []string  `json:"client_caps,omitempty"`
+}
+
+// Stats is a point-in-time snapshot of server-wide counters.
+type Stats struct {
+	StartTime       time.Time       `json:"start_time"`
+	UptimeSeconds   int64           `json:"uptime_seconds"`
+	ActiveSessions  int             `json:"active_sessions"`
+	TotalSessions   uint32          `json:"total_sessions"`
+	TotalRPCs       uint64          `json:"total_rpcs"`
+	SlowClientDrops uint64          `json:"slow_client_drops"`
+	Handlers        []HandlerMetric `json:"handlers,omitempty"`
+}
+
+// HandlerMetric is a snapshot of the timing counters for one
+// namespace/operation pair, the northbound counterpart of
+// miyagi.UIDMetric.
+type HandlerMetric struct {
+	Namespace string  `json:"namespace"`
+	Op        string  `json:"op"`
+	Calls     uint64  `json:"calls"`
+	Errors    uint64  `json:"errors"`
+	AvgMillis float64 `json:"avg_ms"`
+	MaxMillis float64 `json:"max_ms"`
+}
+
+type handlerStats struct {
+	calls  uint64
+	errors uint64
+	total  time.Duration
+	max    time.Duration
+}
+
+// timingKey identifies one handler call site in the timing table.
+type timingKey struct {
+	ns string
+	op CallOp
+}
+
+// recordTiming folds one handler call into the timing counters.
+func (s *Server) recordTiming(call *Call, elapsed time.Duration, err error) {
+	s.timingMu.Lock()
+	defer s.timingMu.Unlock()
+	if s.timings == nil {
+		s.timings = make(map[timingKey]*handlerStats)
+	}
+	key := timingKey{ns: call.Namespace, op: call.Op}
+	st := s.timings[key]
+	if st == nil {
+		st = &handlerStats{}
+		s.timings[key] = st
+	}
+	st.calls++
+	if err != nil {
+		st.errors++
+	}
+	st.total += elapsed
+	if elapsed > st.max {
+		st.max = elapsed
+	}
+}
+
+// HandlerMetrics returns the per-namespace handler timings sorted by
+// namespace then operation.
+func (s *Server) HandlerMetrics() []HandlerMetric {
+	s.timingMu.Lock()
+	defer s.timingMu.Unlock()
+	out := make([]HandlerMetric, 0, len(s.timings))
+	for key, st := range s.timings {
+		hm := HandlerMetric{
+			Namespace: key.ns,
+			Op:        string(key.op),
+			Calls:     st.calls,
+			Errors:    st.errors,
+			MaxMillis: float64(st.max) / float64(time.Millisecond),
+		}
+		if st.calls > 0 {
+			hm.AvgMillis = float64(st.total) / float64(st.calls) / float64(time.Millisecond)
+		}
+		out = append(out, hm)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Op < out[j].Op
+	})
+	return out
+}
+
+// healthReporter is implemented by Miyagi callers (the metrics
+// wrapper) that can report on the last southbound exchange.
+type healthReporter interface {
+	Health() (lastAt time.Time, lastErr error)
+}
+
+// handlePing serves the vendor <ping-server/> RPC: a liveness probe
+// answered entirely from gateway state, so controllers can poll it
+// aggressively without generating device traffic. The Miyagi status is
+// derived from the outcome of the most recent southbound call rather
+// than a fresh one.
+func (s *Server) handlePing(msgID string) string {
+	var b strings.Builder
+	b.WriteString("<pong>")
+	fmt.Fprintf(&b, "<time>%s</time>", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "<uptime-seconds>%d</uptime-seconds>", int64(time.Since(s.startTime).Seconds()))
+	status, detail := "unknown", ""
+	if hr, ok := s.Miyagi.(healthReporter); ok {
+		if lastAt, lastErr := hr.Health(); !lastAt.IsZero() {
+			status = "ok"
+			detail = "<last-call>" + lastAt.UTC().Format(time.RFC3339) + "</last-call>"
+			if lastErr != nil {
+				status = "degraded"
+				detail += "<last-error>" + EscapeXML(lastErr.Error()) + "</last-error>"
+			}
+		}
+	}
+	fmt.Fprintf(&b, "<miyagi><status>%s</status>%s</miyagi>", status, detail)
+	b.WriteString("</pong>")
+	return RPCReply(msgID, b.String())
+}
+
+// Sessions lists the currently active sessions.
+func (s *Server) Sessions() []SessionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]SessionInfo, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		out = append(out, SessionInfo{
+			ID:         sess.ID,
+			Remote:     sess.conn.RemoteAddr().String(),
+			Username:   sess.user,
+			Started:    sess.started,
+			RPCs:       sess.rpcs.Load(),
+			ClientCaps: sess.clientCaps,
+		})
+	}
+	return out
+}
+
+// Stats snapshots the server-wide counters.
+func (s *Server) Stats() Stats {
+	s.mu.Lock()
+	st := Stats{
+		StartTime:       s.startTime,
+		UptimeSeconds:   int64(time.Since(s.startTime).Seconds()),
+		ActiveSessions:  len(s.sessions),
+		TotalSessions:   s.nextSID,
+		TotalRPCs:       s.totalRPCs.Load(),
+		SlowClientDrops: s.slowDrops.Load(),
+	}
+	s.mu.Unlock()
+	st.Handlers = s.HandlerMetrics()
+	return st
+}
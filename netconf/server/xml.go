@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/rahulrock213/switch/netconf/netconfutil"
+)
+
+// Element is a generic XML tree node. Incoming RPC payloads are parsed
+// into Elements so handlers can walk them without per-namespace structs.
+type Element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Text     string     `xml:",chardata"`
+	Children []Element  `xml:",any"`
+}
+
+// Clone returns a deep copy of the element tree; the copy shares no
+// memory with the original, so it stays valid after the original's
+// document is reused.
+func (e *Element) Clone() *Element {
+	c := *e
+	c.Attrs = append([]xml.Attr(nil), e.Attrs...)
+	c.Children = make([]Element, len(e.Children))
+	for i := range e.Children {
+		c.Children[i] = *e.Children[i].Clone()
+	}
+	return &c
+}
+
+// maxParseDepth bounds element nesting in incoming documents.
+const maxParseDepth = 64
+
+// maxParseElements bounds the total element count of one document and
+// maxElementAttrs the attributes on a single element. Both are far
+// beyond any legitimate RPC but stop flat or attribute-heavy payloads
+// that stay within the depth limit from ballooning the parsed tree.
+const (
+	maxParseElements = 1 << 16
+	maxElementAttrs  = 64
+)
+
+// ParseElement parses a single XML document into an Element tree. For
+// robustness against hostile payloads it rejects DTDs (and with them
+// entity definitions) and documents nested deeper than maxParseDepth
+// before building the tree.
+func ParseElement(data []byte) (*Element, error) {
+	if err := checkXMLSafety(data); err != nil {
+		return nil, err
+	}
+	var e Element
+	if err := xml.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// checkXMLSafety walks the raw token stream enforcing the hardening
+// limits without allocating the tree.
+func checkXMLSafety(data []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	depth, elements := 0, 0
+	for {
+		tok, err := dec.RawToken()
+		if err != nil {
+			// Well-formedness errors surface from Unmarshal.
+			return nil
+		}
+		switch t := tok.(type) {
+		case xml.Directive:
+			return errors.New("xml: DTD and entity declarations are not allowed")
+		case xml.StartElement:
+			depth++
+			if depth > maxParseDepth {
+				return fmt.Errorf("xml: document nested deeper than %d elements", maxParseDepth)
+			}
+			elements++
+			if elements > maxParseElements {
+				return fmt.Errorf("xml: document has more than %d elements", maxParseElements)
+			}
+			if len(t.Attr) > maxElementAttrs {
+				return fmt.Errorf("xml: element <%s> has more than %d attributes", t.Name.Local, maxElementAttrs)
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// Local reports the element's local (namespace-free) name.
+func (e *Element) Local() string { return e.XMLName.Local }
+
+// Namespace reports the element's namespace URI.
+func (e *Element) Namespace() string { return e.XMLName.Space }
+
+// Child returns the first child with the given local name, or nil.
+func (e *Element) Child(local string) *Element {
+	for i := range e.Children {
+		if e.Children[i].XMLName.Local == local {
+			return &e.Children[i]
+		}
+	}
+	return nil
+}
+
+// ChildText returns the trimmed text of the first child with the given
+// local name, or "" if there is no such child.
+func (e *Element) ChildText(local string) string {
+	if c := e.Child(local); c != nil {
+		return trimSpace(c.Text)
+	}
+	return ""
+}
+
+// EachChild calls fn for every child with the given local name.
+func (e *Element) EachChild(local string, fn func(*Element)) {
+	for i := range e.Children {
+		if e.Children[i].XMLName.Local == local {
+			fn(&e.Children[i])
+		}
+	}
+}
+
+// Attr returns the value of the named attribute, or "".
+func (e *Element) Attr(local string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func trimSpace(s string) string {
+	return string(bytes.TrimSpace([]byte(s)))
+}
+
+// EscapeXML escapes a string for inclusion in element content.
+func EscapeXML(s string) string {
+	return netconfutil.EscapeXML(s)
+}
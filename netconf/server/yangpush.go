@@ -0,0 +1,239 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// yangPushNS is the RFC 8641 subscribed-notifications namespace the
+// subscription RPCs arrive in.
+const yangPushNS = "urn:ietf:params:xml:ns:yang:ietf-subscribed-notifications"
+
+// subscription is one YANG-Push subscription on a session, either
+// periodic or on-change. period, h and filter may be replaced by
+// <modify-subscription>; the pusher re-reads them under subMu on every
+// tick so changes take effect without a restart.
+type subscription struct {
+	id       uint32
+	period   time.Duration
+	onChange bool
+	h        *Handler
+	filter   *Element
+	stop     chan struct{}
+}
+
+// onChangePollInterval is how often on-change subscriptions sample the
+// device; Miyagi has no change callbacks, so the gateway polls and
+// compares.
+const onChangePollInterval = time.Second
+
+// handleEstablishSubscription serves <establish-subscription>. The
+// <filter> subtree selects a namespace the same way a <get> filter
+// does; <period> is in centiseconds per RFC 8641.
+func (s *Server) handleEstablishSubscription(ctx *Context, msgID string, op *Element) string {
+	sess := ctx.Session
+	if sess == nil {
+		return ErrorReply(msgID, OperationFailed("subscriptions need a session"))
+	}
+	onChange := op.Child("on-change") != nil
+	var period time.Duration
+	if onChange {
+		period = onChangePollInterval
+		if s := op.ChildText("dampening-period"); s != "" {
+			centis, err := strconv.Atoi(s)
+			if err != nil || centis <= 0 {
+				return ErrorReply(msgID, InvalidValue("<dampening-period> must be a positive centisecond count"))
+			}
+			period = time.Duration(centis) * 10 * time.Millisecond
+		}
+	} else {
+		centis, err := strconv.Atoi(op.ChildText("period"))
+		if err != nil || centis <= 0 {
+			return ErrorReply(msgID, InvalidValue("<period> must be a positive centisecond count"))
+		}
+		period = time.Duration(centis) * 10 * time.Millisecond
+	}
+	h, filterCopy, rpcErr := s.resolvePushFilter(op)
+	if rpcErr != nil {
+		return ErrorReply(msgID, rpcErr)
+	}
+	id := sess.addSubscription(period, onChange, h, filterCopy)
+	s.sessWG.Add(1)
+	go func() {
+		defer s.sessWG.Done()
+		sess.runPush(id)
+	}()
+	return RPCReply(msgID, fmt.Sprintf(`<id xmlns=%q>%d</id>`, yangPushNS, id))
+}
+
+// resolvePushFilter extracts the <filter> subtree of a subscription
+// rpc, resolves its namespace handler and deep-copies it: the rpc
+// element's buffer is reused for the next frame, while the filter must
+// outlive the rpc for the whole subscription.
+func (s *Server) resolvePushFilter(op *Element) (*Handler, *Element, error) {
+	filter := op.Child("filter")
+	if filter == nil || len(filter.Children) == 0 {
+		return nil, nil, MissingElement("filter")
+	}
+	sub := &filter.Children[0]
+	h := s.Handler(sub.Namespace())
+	if h == nil || h.Get == nil {
+		return nil, nil, OperationNotSupported("no get handler for namespace %q", sub.Namespace())
+	}
+	return h, sub.Clone(), nil
+}
+
+// handleModifySubscription serves <modify-subscription> (RFC 8641):
+// the cited subscription's period (or dampening period) and filter are
+// replaced in place; leaves absent from the rpc keep their current
+// values.
+func (s *Server) handleModifySubscription(ctx *Context, msgID string, op *Element) string {
+	sess := ctx.Session
+	if sess == nil {
+		return ErrorReply(msgID, OperationFailed("subscriptions need a session"))
+	}
+	id, err := strconv.ParseUint(op.ChildText("id"), 10, 32)
+	if err != nil {
+		return ErrorReply(msgID, InvalidValue("<id> must be a subscription id"))
+	}
+	sess.subMu.Lock()
+	sub, ok := sess.subs[uint32(id)]
+	sess.subMu.Unlock()
+	if !ok {
+		return ErrorReply(msgID, InvalidValue("no subscription %d", id))
+	}
+	periodLeaf := "period"
+	if sub.onChange {
+		periodLeaf = "dampening-period"
+	}
+	var period time.Duration
+	if txt := op.ChildText(periodLeaf); txt != "" {
+		centis, err := strconv.Atoi(txt)
+		if err != nil || centis <= 0 {
+			return ErrorReply(msgID, InvalidValue("<%s> must be a positive centisecond count", periodLeaf))
+		}
+		period = time.Duration(centis) * 10 * time.Millisecond
+	}
+	var h *Handler
+	var filterCopy *Element
+	if op.Child("filter") != nil {
+		var rpcErr error
+		if h, filterCopy, rpcErr = s.resolvePushFilter(op); rpcErr != nil {
+			return ErrorReply(msgID, rpcErr)
+		}
+	}
+	sess.subMu.Lock()
+	if period > 0 {
+		sub.period = period
+	}
+	if filterCopy != nil {
+		sub.h, sub.filter = h, filterCopy
+	}
+	sess.subMu.Unlock()
+	return OKReply(msgID)
+}
+
+// handleDeleteSubscription serves <delete-subscription>.
+func (s *Server) handleDeleteSubscription(ctx *Context, msgID string, op *Element) string {
+	id, err := strconv.ParseUint(op.ChildText("id"), 10, 32)
+	if err != nil {
+		return ErrorReply(msgID, InvalidValue("<id> must be a subscription id"))
+	}
+	if !ctx.Session.removeSubscription(uint32(id)) {
+		return ErrorReply(msgID, InvalidValue("no subscription %d", id))
+	}
+	return OKReply(msgID)
+}
+
+func (sess *Session) addSubscription(period time.Duration, onChange bool, h *Handler, filter *Element) uint32 {
+	sess.subMu.Lock()
+	defer sess.subMu.Unlock()
+	if sess.subs == nil {
+		sess.subs = make(map[uint32]*subscription)
+	}
+	sess.nextSubID++
+	id := sess.nextSubID
+	sess.subs[id] = &subscription{id: id, period: period, onChange: onChange, h: h, filter: filter, stop: make(chan struct{})}
+	return id
+}
+
+func (sess *Session) removeSubscription(id uint32) bool {
+	sess.subMu.Lock()
+	defer sess.subMu.Unlock()
+	sub, ok := sess.subs[id]
+	if !ok {
+		return false
+	}
+	close(sub.stop)
+	delete(sess.subs, id)
+	return true
+}
+
+// stopSubscriptions tears down every subscription, e.g. when the
+// session closes.
+func (sess *Session) stopSubscriptions() {
+	sess.subMu.Lock()
+	defer sess.subMu.Unlock()
+	for id, sub := range sess.subs {
+		close(sub.stop)
+		delete(sess.subs, id)
+	}
+}
+
+// runPush drives one subscription. Periodic subscriptions send a
+// push-update every tick; on-change subscriptions sample every tick
+// but only send a push-change-update when the selected data differs
+// from the previous sample. The subscription parameters are re-read
+// every tick, so a modify-subscription takes effect on the next one.
+func (sess *Session) runPush(id uint32) {
+	sess.subMu.Lock()
+	sub, ok := sess.subs[id]
+	sess.subMu.Unlock()
+	if !ok {
+		return
+	}
+	period := sub.period
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	var last string
+	first := true
+	for {
+		select {
+		case <-sub.stop:
+			return
+		case <-ticker.C:
+			sess.subMu.Lock()
+			h, filter := sub.h, sub.filter
+			if sub.period != period {
+				period = sub.period
+				ticker.Reset(period)
+			}
+			sess.subMu.Unlock()
+			ctx := sess.srv.BackgroundContext()
+			ctx.Session = sess
+			data, err := h.Get(ctx, filter)
+			if err != nil {
+				logf("session %d: push subscription %d: %v", sess.ID, id, err)
+				continue
+			}
+			kind := "push-update"
+			if sub.onChange {
+				if !first && data == last {
+					continue
+				}
+				if !first {
+					kind = "push-change-update"
+				}
+				last = data
+				first = false
+			}
+			event := fmt.Sprintf(`<%s xmlns="urn:ietf:params:xml:ns:yang:ietf-yang-push"><id>%d</id><datastore-contents>%s</datastore-contents></%s>`, kind, id, data, kind)
+			n := Notification{Stream: "yang-push", Time: time.Now(), XML: event}
+			if err := sess.send(NotificationElement(n)); err != nil {
+				sess.removeSubscription(id)
+				return
+			}
+		}
+	}
+}